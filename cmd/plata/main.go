@@ -5,17 +5,37 @@ import (
 	"os/signal"
 	_ "plata/docs"
 	cU "plata/internal/app/cron"
+	"plata/internal/app/hub"
+	"plata/internal/app/leader"
+	"plata/internal/app/outbox"
 	"plata/internal/app/postgres"
+	wh "plata/internal/app/webhook"
 	"plata/internal/clients/exchange"
 	"plata/internal/common/log"
 	"plata/internal/config"
+	"plata/internal/domain/quote"
+	er "plata/internal/repository/event"
+	ler "plata/internal/repository/leader"
+	lr "plata/internal/repository/lock"
+	pr "plata/internal/repository/pair"
+	prr "plata/internal/repository/pricing"
 	qr "plata/internal/repository/quote"
+	rr "plata/internal/repository/rate"
+	wr "plata/internal/repository/webhook"
+	cs "plata/internal/services/conversion"
+	ls "plata/internal/services/lock"
+	ps "plata/internal/services/pair"
+	prs "plata/internal/services/pricing"
 	qs "plata/internal/services/quote"
+	rs "plata/internal/services/rate"
+	ws "plata/internal/services/webhook"
 	"plata/internal/transport/api"
 	"syscall"
 	"time"
 )
 
+const serverShutdownTimeout = 5 * time.Second
+
 // @title Quotes API
 // @version 1.0
 // @description Currency quote service: async updates and retrieval
@@ -39,27 +59,91 @@ func main() {
 		logger.Errorf("Failed to connect to DB: %v", err)
 		return
 	}
+	// Deferred calls run in reverse: HTTP intake stops first, then notifications,
+	// then the updater is drained, leadership released and the DB closed last.
 	defer db.Stop()
 
-	repoQuote := qr.New(db.Primary(), db.Replica())
-	exchClient := exchange.New(cfg.Exchange, logger)
-	service := qs.New(repoQuote, exchClient, logger)
-	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	server := api.NewServer(logger)
-	defer server.Stop(shutdownCtx)
-	handler := api.NewHandler(service)
-	if err = server.InitServer(handler); err != nil {
-		logger.Errorf("Failed to initialize server: %v", err)
+	repoQuote := qr.New(db.Primary(), db.Replica(), cfg.Events.Enabled)
+	pairService := ps.New(pr.New(db.Primary(), db.Replica()), exchange.ProviderNames(cfg.Exchange), cfg.Pairs.CacheTTL, logger)
+	exchClient, err := exchange.NewFromConfig(cfg.Exchange, logger)
+	if err != nil {
+		logger.Errorf("Failed to configure exchange providers: %v", err)
 		return
 	}
-	quoteUpdater := cU.New(cfg.Cron, *repoQuote, exchClient, logger)
-	defer quoteUpdater.Stop()
+	updates := hub.New(logger)
+	repoWebhook := wr.New(db.Primary(), db.Replica())
+	webhookService := ws.New(repoWebhook, cfg.Webhooks.AllowedHosts, logger)
+	service := qs.New(repoQuote, pairService, exchClient, updates, webhookService, logger)
+
+	elector := leader.New(cfg.Cron, ler.New(db.Primary()), logger)
+	defer elector.Stop()
+	quoteUpdater := cU.New(cfg.Cron, repoQuote, pairService, exchClient, logger)
+	repoRate := rr.New(db.Primary(), db.Replica())
+	quoteUpdater.SetCandleRepository(repoRate)
+	defer func() {
+		drainCtx, cancel := context.WithTimeout(context.Background(), cfg.Cron.DrainTimeout)
+		defer cancel()
+		quoteUpdater.Stop(drainCtx)
+	}()
 	if err = quoteUpdater.Run(); err != nil {
 		logger.Errorf("Failed to start quote updater: %v", err)
 		return
 	}
+	elector.OnChange(quoteUpdater.SetLeader)
+	quoteUpdater.OnUpdate(updates.Publish)
+
+	dispatcher := wh.New(cfg.Webhooks, cfg.Cron.InstanceID, repoWebhook, logger)
+	quoteUpdater.OnUpdate(func(q *quote.Quote) {
+		if q.CallbackURL != "" {
+			dispatcher.Wake()
+		}
+	})
+	dispatcher.Start(context.Background())
+	defer dispatcher.Stop()
+
+	if cfg.Events.Enabled {
+		publisher, err := outbox.NewPublisher(cfg.Events)
+		if err != nil {
+			logger.Errorf("Failed to configure event publisher: %v", err)
+			return
+		}
+		relay := outbox.New(cfg.Events, cfg.Cron.InstanceID, er.New(db.Primary()), publisher, logger)
+		quoteUpdater.OnUpdate(func(*quote.Quote) { relay.Wake() })
+		relay.Start(context.Background())
+		defer relay.Stop()
+	}
+	elector.Start(context.Background())
+	if cfg.Cron.Notify.Enabled {
+		listener := postgres.NewListener(cfg.Postgres, logger)
+		if err = listener.Listen(qr.ChannelQuoteRequested, quoteUpdater.HandleQuoteRequested); err != nil {
+			logger.Errorf("Failed to subscribe to quote requests: %v", err)
+			return
+		}
+		// Updates written by the updater of other instances.
+		if err = listener.Listen(qr.ChannelQuoteUpdated, updates.HandleNotification); err != nil {
+			logger.Errorf("Failed to subscribe to quote updates: %v", err)
+			return
+		}
+		listener.Start(context.Background())
+		defer listener.Stop()
+	}
+
+	server := api.NewServer(cfg.Server, logger)
+	rateService := rs.New(repoRate, logger)
+	conversionService := cs.New(cfg.Convert, repoRate, logger)
+	lockService := ls.New(cfg.Locks, lr.New(db.Primary()), repoRate, pairService, logger)
+	pricingService := prs.New(prr.New(db.Primary(), db.Replica()), cfg.Pricing.CacheTTL, logger)
+	handler := api.NewHandler(service, pairService, webhookService, rateService, conversionService, lockService, pricingService, elector, cfg.Server)
+	if err = server.InitServer(handler); err != nil {
+		logger.Errorf("Failed to initialize server: %v", err)
+		return
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		server.Stop(shutdownCtx)
+	}()
 
 	<-ctx.Done()
 	logger.Info("Shutdown signal received")
-	defer cancel()
 }
@@ -18,9 +18,46 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/quotes/latest": {
+        "/admin/pairs": {
             "get": {
-                "description": "Returns the most recent quote for a currency pair (e.g. EUR/USD)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List currency pairs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/plata_internal_domain_pair.Pair"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
@@ -28,20 +65,84 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "quotes"
+                    "admin"
                 ],
-                "summary": "Get latest quote",
+                "summary": "Add currency pair",
                 "parameters": [
                     {
-                        "enum": [
-                            "EUR/USD",
-                            "EUR/MXN",
-                            "EUR/RUB"
-                        ],
-                        "type": "string",
                         "description": "Currency pair",
-                        "name": "currency",
-                        "in": "query",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.AddPairRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/plata_internal_domain_pair.Pair"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/pairs/{base}/{target}/disable": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Disable currency pair",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "USD",
+                        "description": "Quote currency",
+                        "name": "target",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -57,18 +158,73 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/plata_internal_domain_quote.Quote"
+                                            "$ref": "#/definitions/plata_internal_domain_pair.Pair"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
+                    "404": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
                         "description": "Error response",
                         "schema": {
                             "$ref": "#/definitions/internal_transport_api.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/admin/pairs/{base}/{target}/enable": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Enable currency pair",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Base currency",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "USD",
+                        "description": "Quote currency",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/plata_internal_domain_pair.Pair"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
                     "404": {
                         "description": "Error response",
@@ -85,9 +241,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/quotes/update": {
+        "/admin/pricing-rules": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List pricing rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/plata_internal_domain_pricing.Rule"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Asynchronously request a quote update for a currency pair",
+                "description": "Bid and ask are the mid rate minus and plus markup_bps of it and markup_pips pips, from min_amount of the base currency.\nOmit tenant or pair to apply the rule to all of them; the most specific matching rule wins.",
                 "consumes": [
                     "application/json"
                 ],
@@ -95,23 +289,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "quotes"
+                    "admin"
                 ],
-                "summary": "Update a quote",
+                "summary": "Add pricing rule",
                 "parameters": [
                     {
-                        "description": "Currency pair",
+                        "description": "Pricing rule",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_transport_api.UpdateQuoteRequest"
+                            "$ref": "#/definitions/internal_transport_api.AddPricingRuleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -121,7 +315,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/internal_transport_api.UpdateQuoteResponse"
+                                            "$ref": "#/definitions/plata_internal_domain_pricing.Rule"
                                         }
                                     }
                                 }
@@ -134,6 +328,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_transport_api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Error response",
                         "schema": {
@@ -143,24 +343,97 @@ const docTemplate = `{
                 }
             }
         },
-        "/quotes/{id}": {
-            "get": {
+        "/admin/pricing-rules/{id}": {
+            "delete": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "quotes"
+                    "admin"
                 ],
-                "summary": "Retrieve quote by ID",
+                "summary": "Delete pricing rule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Quote update ID",
+                        "type": "integer",
+                        "description": "Pricing rule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/convert": {
+            "get": {
+                "description": "Converts with the latest rates, or the last rates known at at. Pairs are used directly, inverted or triangulated through a pivot currency.\nThe result is rounded to the minor units of the target currency; rates older than the staleness limit are rejected with 409.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Convert an amount",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Source currency, e.g. EUR",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency, e.g. MXN",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Amount to convert, e.g. 125.40",
+                        "name": "amount",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time of the rates to use, defaults to now",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "half_even (default), half_up, up, down, ceiling or floor",
+                        "name": "rounding",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -173,7 +446,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/plata_internal_domain_quote.Quote"
+                                            "$ref": "#/definitions/plata_internal_domain_conversion.Conversion"
                                         }
                                     }
                                 }
@@ -192,6 +465,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_transport_api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Error response",
                         "schema": {
@@ -200,9 +479,811 @@ const docTemplate = `{
                     }
                 }
             }
-        }
+        },
+        "/quotes/latest": {
+            "get": {
+                "description": "Returns the most recent done quote for a currency pair (e.g. EUR/USD) with its age_seconds.\nWith max_age (e.g. 5m) an older quote returns 409, or 202 with the update_id of a refresh when refresh is on (server default, or ?refresh=).\nA missing quote is also refreshed with 202 when refresh is on.\nDone quotes carry mid, bid, ask and the rule_id of the pricing rule matching the X-Tenant-ID header and amount.\nX-Tenant-ID is trusted as sent: the gateway in front of the service must authenticate the client and set it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotes"
+                ],
+                "summary": "Get latest quote",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency pair",
+                        "name": "currency",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Maximum age of the quote, e.g. 5m",
+                        "name": "max_age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Request an update when the quote is stale or missing",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tenant the quote is priced for, set by the authenticating gateway",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Amount of the base currency selecting the pricing tier",
+                        "name": "amount",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/plata_internal_domain_quote.Quote"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/internal_transport_api.RefreshQuoteResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quotes/lock": {
+            "post": {
+                "description": "Snapshots the latest rate of the pair, raised by markup_bps, into a lock guaranteed until expires_at.\nA repeated Idempotency-Key returns the lock created first, or 409 when the terms differ.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotes"
+                ],
+                "summary": "Lock a quote",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Idempotency key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Currency pair, TTL and markup",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.LockQuoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/plata_internal_domain_quote.Lock"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quotes/lock/{token}/consume": {
+            "post": {
+                "description": "Marks the lock used; succeeds exactly once and only before expires_at.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotes"
+                ],
+                "summary": "Consume a quote lock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lock token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/plata_internal_domain_quote.Lock"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quotes/stream": {
+            "get": {
+                "description": "Emits a ` + "`" + `quote` + "`" + ` event (id = quote ID) whenever a done quote is written for one of the pairs.\nSend Last-Event-ID to replay the quotes missed since that event. Slow clients are disconnected and should resume the same way.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "quotes"
+                ],
+                "summary": "Stream quote updates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated currency pairs, e.g. EUR/USD,EUR/MXN",
+                        "name": "currency",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last quote received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "quote events, data is the JSON quote",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quotes/update": {
+            "post": {
+                "description": "Asynchronously request a quote update for a currency pair.\nAn optional callback_url receives an HMAC-SHA256 signed POST (X-Plata-Signature) when the update finishes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotes"
+                ],
+                "summary": "Update a quote",
+                "parameters": [
+                    {
+                        "description": "Currency pair",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.UpdateQuoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/internal_transport_api.UpdateQuoteResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quotes/{id}": {
+            "get": {
+                "description": "Status is in_progress until the update reaches a terminal state: done, failed or expired.\nWith wait (e.g. 10s, capped by the server) the request blocks until the quote is terminal or the time elapses.\nDone quotes carry mid, bid, ask and the rule_id of the pricing rule matching the X-Tenant-ID header and amount.\nX-Tenant-ID is trusted as sent: the gateway in front of the service must authenticate the client and set it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotes"
+                ],
+                "summary": "Retrieve quote by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quote update ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Maximum time to wait for a terminal status, e.g. 10s",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tenant the quote is priced for, set by the authenticating gateway",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Amount of the base currency selecting the pricing tier",
+                        "name": "amount",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/plata_internal_domain_quote.Quote"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quotes/{id}/callbacks": {
+            "get": {
+                "description": "Deliveries of the callback_url given when requesting the update, with every attempt made",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotes"
+                ],
+                "summary": "List quote callbacks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quote update ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/plata_internal_domain_webhook.Delivery"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/at": {
+            "get": {
+                "description": "The last rate recorded for the pair at or before at",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Rate at a point in time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency pair",
+                        "name": "currency",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 time",
+                        "name": "at",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/plata_internal_domain_rate.Rate"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/candles": {
+            "get": {
+                "description": "Open, high, low and close rate with the sample count of every interval bucket starting in [from, to) that holds at least one rate.\nBuckets are aligned to 2000-01-01T00:00:00Z and from is rounded down to the start of its bucket.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Rate candles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency pair",
+                        "name": "currency",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket size, e.g. 1m, 1h or 24h",
+                        "name": "interval",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 start, defaults to 24h before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 end, defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/plata_internal_domain_rate.CandleSeries"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/history": {
+            "get": {
+                "description": "Rates of done quotes fetched in [from, to), oldest first. With interval (e.g. 1h) only the last rate of every bucket is returned.\nPass next_cursor back as cursor to get the following page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Rate history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency pair",
+                        "name": "currency",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 start, defaults to 24h before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 end, defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket size of at least 1s, e.g. 15m or 1h",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/plata_internal_domain_rate.Page"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "Returns this instance ID and the ID of the instance currently running leader-only jobs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "status"
+                ],
+                "summary": "Instance status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_transport_api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/internal_transport_api.StatusResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/internal_transport_api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "internal_transport_api.AddPairRequest": {
+            "type": "object",
+            "required": [
+                "pair"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "precision": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_transport_api.AddPricingRuleRequest": {
+            "type": "object",
+            "properties": {
+                "markup_bps": {
+                    "type": "integer"
+                },
+                "markup_pips": {
+                    "type": "string",
+                    "example": "1.5"
+                },
+                "min_amount": {
+                    "type": "string",
+                    "example": "10000"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "tenant": {
+                    "type": "string"
+                }
+            }
+        },
         "internal_transport_api.ErrorResponse": {
             "type": "object",
             "properties": {
@@ -215,6 +1296,54 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_transport_api.LockQuoteRequest": {
+            "type": "object",
+            "required": [
+                "currency"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "markup_bps": {
+                    "type": "integer"
+                },
+                "ttl_seconds": {
+                    "description": "TTLSeconds is how long the rate is guaranteed, the server default when omitted.",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_transport_api.RefreshQuoteResponse": {
+            "type": "object",
+            "properties": {
+                "quote": {
+                    "description": "Quote is the stale quote, omitted when the pair has none yet.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/plata_internal_domain_quote.Quote"
+                        }
+                    ]
+                },
+                "update_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_transport_api.StatusResponse": {
+            "type": "object",
+            "properties": {
+                "instance_id": {
+                    "type": "string"
+                },
+                "is_leader": {
+                    "type": "boolean"
+                },
+                "leader_id": {
+                    "type": "string"
+                }
+            }
+        },
         "internal_transport_api.SuccessResponse": {
             "type": "object",
             "properties": {
@@ -233,8 +1362,11 @@ const docTemplate = `{
                 "currency"
             ],
             "properties": {
+                "callback_url": {
+                    "description": "CallbackURL receives a signed POST once the update is done, failed or expired; its host must be allow-listed.",
+                    "type": "string"
+                },
                 "currency": {
-                    "description": "например: EUR/MXN",
                     "type": "string"
                 }
             }
@@ -247,21 +1379,230 @@ const docTemplate = `{
                 }
             }
         },
+        "plata_internal_domain_conversion.Conversion": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "125.40"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/plata_internal_domain_conversion.Leg"
+                    }
+                },
+                "quote_id": {
+                    "description": "QuoteID is the quote the rate comes from, set unless it is triangulated.",
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "18.7345000000"
+                },
+                "rate_time": {
+                    "description": "RateTime is the fetch time of the oldest leg.",
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string",
+                    "example": "2349.30"
+                },
+                "rounding": {
+                    "$ref": "#/definitions/plata_internal_domain_conversion.RoundingMode"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "plata_internal_domain_conversion.Leg": {
+            "type": "object",
+            "properties": {
+                "fetched_at": {
+                    "type": "string"
+                },
+                "inverted": {
+                    "type": "boolean"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "quote_id": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "18.734500"
+                }
+            }
+        },
+        "plata_internal_domain_conversion.RoundingMode": {
+            "type": "string",
+            "enum": [
+                "half_even",
+                "half_up",
+                "up",
+                "down",
+                "ceiling",
+                "floor"
+            ],
+            "x-enum-varnames": [
+                "RoundHalfEven",
+                "RoundHalfUp",
+                "RoundUp",
+                "RoundDown",
+                "RoundCeiling",
+                "RoundFloor"
+            ]
+        },
+        "plata_internal_domain_pair.Pair": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "precision": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                }
+            }
+        },
+        "plata_internal_domain_pricing.Rule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "markup_bps": {
+                    "type": "integer"
+                },
+                "markup_pips": {
+                    "type": "string",
+                    "example": "1.5"
+                },
+                "min_amount": {
+                    "type": "string",
+                    "example": "10000"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "tenant": {
+                    "type": "string"
+                }
+            }
+        },
+        "plata_internal_domain_quote.Lock": {
+            "type": "object",
+            "properties": {
+                "consumed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "idempotency_key": {
+                    "type": "string"
+                },
+                "markup_bps": {
+                    "type": "integer"
+                },
+                "mid_rate": {
+                    "type": "string",
+                    "example": "1.082300"
+                },
+                "quote_id": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "1.083382"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
         "plata_internal_domain_quote.Quote": {
             "type": "object",
             "properties": {
                 "amount": {
-                    "type": "number"
+                    "type": "string",
+                    "example": "1.082300"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
                 },
                 "currency": {
                     "type": "string"
                 },
+                "derived": {
+                    "type": "boolean"
+                },
+                "error_code": {
+                    "type": "string"
+                },
+                "error_message": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
                 "idempotency_key": {
                     "type": "string"
                 },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "origin": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "sources": {
+                    "type": "integer"
+                },
+                "spread": {
+                    "type": "string",
+                    "example": "0.000200"
+                },
                 "status": {
                     "$ref": "#/definitions/plata_internal_domain_quote.Status"
                 },
@@ -275,12 +1616,170 @@ const docTemplate = `{
             "enum": [
                 0,
                 1,
-                2
+                2,
+                3,
+                4
             ],
             "x-enum-varnames": [
                 "StatusUnspecified",
                 "StatusInProgress",
-                "StatusDone"
+                "StatusDone",
+                "StatusFailed",
+                "StatusExpired"
+            ]
+        },
+        "plata_internal_domain_rate.Candle": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "string",
+                    "example": "1.082700"
+                },
+                "high": {
+                    "type": "string",
+                    "example": "1.083100"
+                },
+                "low": {
+                    "type": "string",
+                    "example": "1.081900"
+                },
+                "open": {
+                    "type": "string",
+                    "example": "1.082300"
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "plata_internal_domain_rate.CandleSeries": {
+            "type": "object",
+            "properties": {
+                "candles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/plata_internal_domain_rate.Candle"
+                    }
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "pair": {
+                    "type": "string"
+                }
+            }
+        },
+        "plata_internal_domain_rate.Page": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/plata_internal_domain_rate.Rate"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "plata_internal_domain_rate.Rate": {
+            "type": "object",
+            "properties": {
+                "bucket": {
+                    "description": "Bucket is the start of the interval the rate closes, set for downsampled history.",
+                    "type": "string"
+                },
+                "fetched_at": {
+                    "type": "string"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "quote_id": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "1.082300"
+                }
+            }
+        },
+        "plata_internal_domain_webhook.Attempt": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "attempted_at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "plata_internal_domain_webhook.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "log": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/plata_internal_domain_webhook.Attempt"
+                    }
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "quote_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/plata_internal_domain_webhook.Status"
+                }
+            }
+        },
+        "plata_internal_domain_webhook.Status": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StatusPending",
+                "StatusDelivered",
+                "StatusFailed"
             ]
         }
     }
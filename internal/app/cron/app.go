@@ -2,47 +2,128 @@ package cron
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"plata/internal/clients/exchange"
+	"plata/internal/common/backoff"
 	"plata/internal/common/log"
 	"plata/internal/config"
+	"plata/internal/domain/pair"
 	"plata/internal/domain/quote"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/robfig/cron/v3"
-	qr "plata/internal/repository/quote"
+	"github.com/shopspring/decimal"
 )
 
 type Service struct {
-	repo     qr.Repository
-	fetcher  exchange.ExternalRateFetcher
-	cron     *cron.Cron
-	schedule string
-	log      log.Logger
+	repo             QuoteRepository
+	pairs            PairCatalog
+	fetcher          exchange.ExternalRateFetcher
+	cron             *cron.Cron
+	schedule         string
+	pivot            string
+	unsupportedBases map[string]struct{}
+	maxAttempts      int
+	maxAge           time.Duration
+	retry            backoff.Backoff
+	instanceID       string
+	batchSize        int
+	leaseTTL         time.Duration
+	workers          int
+	tickDeadline     time.Duration
+	purgeSchedule    string
+	prewarmSchedule  string
+	runsRetention    time.Duration
+	outboxRetention  time.Duration
+	candles          CandleRepository
+	candlesCfg       config.CandlesConfig
+	requested        *debouncer
+	log              log.Logger
+
+	// ctx is the parent of every job run and is cancelled once Stop gives up draining.
+	ctx    context.Context
+	cancel context.CancelFunc
+	jobs   sync.WaitGroup
+
+	mu            sync.Mutex
+	leaderEntries map[cron.EntryID]string
+	stopping      bool
+	onUpdate      []func(q *quote.Quote)
 }
 
-func New(cfg config.CronConfig, repo qr.Repository, fetcher exchange.ExternalRateFetcher, log log.Logger) *Service {
-	return &Service{
-		cron:     cron.New(),
-		repo:     repo,
-		fetcher:  fetcher,
-		schedule: cfg.Schedule,
-		log:      log,
+const (
+	defaultBatchSize = 100
+	defaultLeaseTTL  = time.Minute
+	defaultWorkers   = 4
+)
+
+func New(
+	cfg config.CronConfig,
+	repo QuoteRepository,
+	pairs PairCatalog,
+	fetcher exchange.ExternalRateFetcher,
+	log log.Logger,
+) *Service {
+	if cfg.InstanceID == "" {
+		cfg.InstanceID = config.DefaultInstanceID()
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = defaultLeaseTTL
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
 	}
+	unsupported := make(map[string]struct{}, len(cfg.Triangulation.UnsupportedBases))
+	for _, base := range cfg.Triangulation.UnsupportedBases {
+		unsupported[base] = struct{}{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		ctx:              ctx,
+		cancel:           cancel,
+		cron:             cron.New(),
+		repo:             repo,
+		pairs:            pairs,
+		fetcher:          fetcher,
+		schedule:         cfg.Schedule,
+		pivot:            cfg.Triangulation.Pivot,
+		unsupportedBases: unsupported,
+		maxAttempts:      cfg.MaxAttempts,
+		maxAge:           cfg.MaxAge,
+		retry:            backoff.New(cfg.Retry),
+		instanceID:       cfg.InstanceID,
+		batchSize:        cfg.BatchSize,
+		leaseTTL:         cfg.LeaseTTL,
+		workers:          cfg.Workers,
+		tickDeadline:     cfg.TickDeadline,
+		purgeSchedule:    cfg.PurgeSchedule,
+		prewarmSchedule:  cfg.PrewarmSchedule,
+		runsRetention:    cfg.RunsRetention,
+		outboxRetention:  cfg.OutboxRetention,
+		candlesCfg:       cfg.Candles,
+		log:              log,
+	}
+	s.requested = newDebouncer(cfg.Notify.Debounce, s.updateRequested)
+	return s
 }
 
 func (s *Service) Run() error {
+	if err := s.validateLeaderJobs(); err != nil {
+		return err
+	}
 	s.log.Infof("Cron job registered with schedule: %s", s.schedule)
 	_, err := s.cron.AddFunc(s.schedule, func() {
-		s.log.Info("Running scheduled quote update...")
-		ctx := context.Background()
-		quotes, err := s.repo.GetInProgressQuotes(ctx)
-		s.log.Infof("Found %d in-progress quotes to update", len(quotes))
-		if err != nil {
-			s.log.Errorf("Failed to fetch in-progress quotes: %v", err)
-			return
-		}
-		s.updateQuotesBatch(ctx, quotes)
+		s.runJob(func(ctx context.Context) {
+			s.log.Info("Running scheduled quote update...")
+			s.UpdateQuotes(ctx)
+		})
 	})
 	if err != nil {
 		return err
@@ -51,61 +132,343 @@ func (s *Service) Run() error {
 	return nil
 }
 
-func (s *Service) updateQuotesBatch(ctx context.Context, quotes []*quote.Quote) {
-	type quoteGroup struct {
-		targets []string
-		quotes  []*quote.Quote
+func (s *Service) UpdateQuotes(ctx context.Context) *quote.RunSummary {
+	return s.updateQuotes(ctx, "")
+}
+
+// updateQuotes claims and processes due quotes until none are left, restricted to
+// pairs quoted in base unless it is empty.
+func (s *Service) updateQuotes(ctx context.Context, base string) *quote.RunSummary {
+	run := quote.NewRunSummary(uuid.NewString(), quote.OriginRequest, time.Now())
+	tickCtx := ctx
+	if s.tickDeadline > 0 {
+		var cancel context.CancelFunc
+		tickCtx, cancel = context.WithTimeout(ctx, s.tickDeadline)
+		defer cancel()
+	}
+	for {
+		quotes, err := s.repo.ClaimDueQuotes(tickCtx, s.instanceID, time.Now(), s.leaseTTL, s.batchSize, base)
+		if err != nil {
+			s.log.Errorf("Failed to claim due quotes: %v", err)
+			run.Error = err.Error()
+			break
+		}
+		s.log.Infof("Claimed %d due quotes to update: instance=%s", len(quotes), s.instanceID)
+		if len(quotes) == 0 {
+			break
+		}
+		s.updateQuotesBatch(tickCtx, quotes, run)
+		if len(quotes) < s.batchSize || tickCtx.Err() != nil {
+			break
+		}
 	}
-	grouped := make(map[string]*quoteGroup, len(quotes))
+	s.finishRun(ctx, run)
+	return run
+}
+
+func (s *Service) finishRun(ctx context.Context, run *quote.RunSummary) {
+	run.Finish(time.Now())
+	s.log.Infof("Quote update run finished: id=%s updated=%d failed=%d skipped=%d duration=%s",
+		run.ID, run.Updated, run.Failed, run.Skipped, run.FinishedAt.Sub(run.StartedAt),
+	)
+	if ctx.Err() != nil {
+		// Still record runs interrupted by shutdown.
+		ctx = context.WithoutCancel(ctx)
+	}
+	if err := s.repo.SaveRun(ctx, run); err != nil {
+		s.log.Errorf("Failed to save update run summary: %v", err)
+	}
+}
+
+type groupKey struct {
+	base     string
+	provider string
+}
+
+type quoteGroup struct {
+	targets []string
+	quotes  []*quote.Quote
+}
+
+func (s *Service) updateQuotesBatch(ctx context.Context, quotes []*quote.Quote, run *quote.RunSummary) {
+	grouped := make(map[groupKey]*quoteGroup, len(quotes))
 	for _, q := range quotes {
 		base, target, ok := parseCurrencyPair(q.Currency)
 		if !ok {
 			s.log.Warnf("Invalid currency pair format: %s", q.Currency)
+			s.fail(ctx, q, quote.ErrorCodeInvalidPair, fmt.Errorf("invalid currency pair format: %s", q.Currency))
+			run.Group(q.Currency).Failed++
+			continue
+		}
+		if s.maxAge > 0 && time.Since(q.CreatedAt) > s.maxAge {
+			if err := s.expire(ctx, q); err != nil {
+				run.Group(base).Failed++
+			} else {
+				run.Group(base).Skipped++
+			}
+			continue
+		}
+		key := groupKey{base: base, provider: s.providerOverride(ctx, q.Currency)}
+		if _, exists := grouped[key]; !exists {
+			grouped[key] = &quoteGroup{}
+		}
+		grouped[key].targets = append(grouped[key].targets, target)
+		grouped[key].quotes = append(grouped[key].quotes, q)
+	}
+
+	s.runGroups(ctx, grouped, run)
+}
+
+// updateGroup fetches and stores the rates of one base currency group,
+// failures are recorded on the affected quotes and never abort other groups.
+func (s *Service) updateGroup(ctx context.Context, key groupKey, group *quoteGroup, summary *quote.GroupSummary) {
+	base := key.base
+	targets := unique(group.targets)
+	fetchCtx := ctx
+	if key.provider != "" {
+		fetchCtx = exchange.WithProvider(ctx, key.provider)
+	}
+	rates, legs, err := s.fetchRates(fetchCtx, base, targets)
+	if err != nil && ctx.Err() != nil {
+		// Cancelled rather than failed: leave the quotes to be reclaimed once their lease expires.
+		s.log.Warnf("Abandoned group base=%s targets=%v: %v", base, targets, ctx.Err())
+		summary.Error = ctx.Err().Error()
+		summary.Skipped += len(group.quotes)
+		return
+	}
+	if err != nil {
+		s.log.Errorf("Failed to fetch rates for group base=%s targets=%v: %v", base, targets, err)
+		summary.Error = err.Error()
+		for _, q := range group.quotes {
+			s.recordFailure(ctx, q, quote.ErrorCodeProvider, err)
+			summary.Failed++
+		}
+		return
+	}
+	for _, skipped := range rates.Skipped {
+		s.log.Warnf("Skipped provider for base=%s: %v", base, skipped)
+	}
+	for _, q := range group.quotes {
+		_, target, _ := parseCurrencyPair(q.Currency)
+		rate, ok := rates.Values[target]
+		if !ok {
+			s.log.Errorf("Missing rate in API response: %s", q.Currency)
+			s.recordFailure(ctx, q, quote.ErrorCodeRateUnavailable, fmt.Errorf("rate for %s missing in provider response", q.Currency))
+			summary.Failed++
 			continue
 		}
-		if _, exists := grouped[base]; !exists {
-			grouped[base] = &quoteGroup{}
+		// Fill a copy so a quote the DB rejected is neither counted nor published as done.
+		now := time.Now()
+		done := *q
+		done.Attempts++
+		done.LastAttemptAt = &now
+		done.NextAttemptAt = nil
+		done.Amount = rate.Round(q.Scale)
+		done.Status = quote.StatusDone
+		done.Provider = rates.ProviderFor(target)
+		done.Sources = rates.SourcesFor(target)
+		done.Spread = rates.Spread[target].Round(q.Scale)
+		done.Legs = legs[target]
+		done.Derived = len(done.Legs) > 0
+		done.UpdatedAt = now
+		if err = s.store(ctx, &done); err != nil {
+			s.log.Errorf("Failed to update quote %s in DB: %v", q.ID, err)
+			summary.Failed++
+			summary.Error = err.Error()
+			continue
 		}
-		grouped[base].targets = append(grouped[base].targets, target)
-		grouped[base].quotes = append(grouped[base].quotes, q)
+		*q = done
+		summary.Updated++
+		s.notifyUpdate(q)
+		s.log.Infof("Quote updated: id=%s currency=%s amount=%s provider=%s sources=%d derived=%t updated_at=%s",
+			q.ID, q.Currency, q.Amount.StringFixed(q.Scale), q.Provider, q.Sources, q.Derived, q.UpdatedAt.Format(time.RFC3339),
+		)
 	}
+}
 
-	for base, group := range grouped {
-		targets := unique(group.targets)
+func (s *Service) providerOverride(ctx context.Context, currency string) string {
+	if s.pairs == nil {
+		return ""
+	}
+	p, err := s.pairs.Get(ctx, currency)
+	if err != nil {
+		if !errors.Is(err, pair.ErrPairNotFound) {
+			s.log.Warnf("Failed to look up currency pair %s: %v", currency, err)
+		}
+		return ""
+	}
+	return p.Provider
+}
+
+// recordFailure counts a failed attempt and schedules the next one with exponential backoff,
+// the quote is marked failed once maxAttempts is reached.
+func (s *Service) recordFailure(ctx context.Context, q *quote.Quote, code string, cause error) {
+	now := time.Now()
+	q.Attempts++
+	q.LastAttemptAt = &now
+	q.LastError = cause.Error()
+	if s.maxAttempts > 0 && q.Attempts >= s.maxAttempts {
+		s.fail(ctx, q, code, cause)
+		return
+	}
+	next := now.Add(s.retry.Delay(q.Attempts))
+	q.NextAttemptAt = &next
+	q.UpdatedAt = now
+	if err := s.store(ctx, q); err != nil {
+		s.log.Errorf("Failed to record attempt for quote %s: %v", q.ID, err)
+		return
+	}
+	s.log.Infof("Quote retry scheduled: id=%s attempts=%d next_attempt_at=%s", q.ID, q.Attempts, next.Format(time.RFC3339))
+}
+
+func (s *Service) fail(ctx context.Context, q *quote.Quote, code string, cause error) {
+	q.Status = quote.StatusFailed
+	q.NextAttemptAt = nil
+	q.ErrorCode = code
+	q.ErrorMessage = cause.Error()
+	q.UpdatedAt = time.Now()
+	if err := s.store(ctx, q); err != nil {
+		s.log.Errorf("Failed to mark quote %s as failed: %v", q.ID, err)
+		return
+	}
+	s.notifyUpdate(q)
+	s.log.Warnf("Quote failed: id=%s currency=%s attempts=%d code=%s error=%s", q.ID, q.Currency, q.Attempts, code, q.ErrorMessage)
+}
+
+// expire marks a quote pending for longer than maxAge as expired, a failed write is
+// returned and not published.
+func (s *Service) expire(ctx context.Context, q *quote.Quote) error {
+	q.Status = quote.StatusExpired
+	q.ErrorCode = quote.ErrorCodeExpired
+	q.ErrorMessage = fmt.Sprintf("quote still pending after %s", s.maxAge)
+	q.UpdatedAt = time.Now()
+	if err := s.store(ctx, q); err != nil {
+		s.log.Errorf("Failed to mark quote %s as expired: %v", q.ID, err)
+		return err
+	}
+	s.notifyUpdate(q)
+	s.log.Warnf("Quote expired: id=%s currency=%s created_at=%s", q.ID, q.Currency, q.CreatedAt.Format(time.RFC3339))
+	return nil
+}
+
+// fetchRates asks the provider for the base directly and falls back to the pivot
+// currency when the base is rejected or configured as unsupported.
+func (s *Service) fetchRates(ctx context.Context, base string, targets []string) (*exchange.Rates, map[string][]string, error) {
+	if _, unsupported := s.unsupportedBases[base]; !unsupported || s.pivot == "" || base == s.pivot {
 		rates, err := s.fetcher.FetchRates(ctx, base, targets)
-		if err != nil {
-			s.log.Errorf("Failed to fetch rates for group base=%s targets=%v: %v", base, targets, err)
-			return
+		if err == nil || !errors.Is(err, exchange.ErrUnsupportedBase) || s.pivot == "" || base == s.pivot {
+			return rates, nil, err
 		}
-		for _, q := range group.quotes {
-			_, target, ok := parseCurrencyPair(q.Currency)
-			if !ok {
-				continue
-			}
-			rate, ok := rates[target]
-			if !ok {
-				s.log.Errorf("Missing rate in API response: %s", q.Currency)
-				return
-			}
-			q.Amount = rate
-			q.Status = quote.StatusDone
-			q.UpdatedAt = time.Now()
-			if err = s.repo.Update(ctx, q); err != nil {
-				s.log.Errorf("Failed to update quote in DB: %v", err)
-				return
-			}
-			s.log.Infof("Quote updated: id=%s currency=%s amount=%.4f updated_at=%s",
-				q.ID, q.Currency, q.Amount, q.UpdatedAt.Format(time.RFC3339),
-			)
+		s.log.Warnf("Base %s rejected by provider, triangulating via %s: %v", base, s.pivot, err)
+	}
+	return s.triangulate(ctx, base, targets)
+}
+
+func (s *Service) triangulate(ctx context.Context, base string, targets []string) (*exchange.Rates, map[string][]string, error) {
+	symbols := []string{base}
+	for _, target := range targets {
+		if target != s.pivot {
+			symbols = append(symbols, target)
 		}
 	}
+	pivotRates, err := s.fetcher.FetchRates(ctx, s.pivot, unique(symbols))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch pivot rates via %s: %w", s.pivot, err)
+	}
+	baseRate, ok := pivotRates.Values[base]
+	if !ok || baseRate.IsZero() {
+		return nil, nil, fmt.Errorf("pivot %s has no rate for base %s", s.pivot, base)
+	}
+
+	baseLeg := s.pivot + "/" + base
+	rates := &exchange.Rates{
+		Provider:  pivotRates.Provider,
+		Providers: make(map[string]string, len(targets)),
+		Values:    make(map[string]decimal.Decimal, len(targets)),
+		Sources:   make(map[string]int, len(targets)),
+		Spread:    make(map[string]decimal.Decimal, len(targets)),
+		Skipped:   pivotRates.Skipped,
+	}
+	// The relative spreads of the legs add up in the derived rate.
+	baseSpread := relativeSpread(pivotRates, base)
+	legs := make(map[string][]string, len(targets))
+	for _, target := range targets {
+		if target == s.pivot {
+			rates.Values[target] = decimal.NewFromInt(1).Div(baseRate)
+			rates.Spread[target] = rates.Values[target].Mul(baseSpread)
+			rates.Sources[target] = pivotRates.SourcesFor(base)
+			rates.Providers[target] = pivotRates.ProviderFor(base)
+			legs[target] = []string{baseLeg}
+			continue
+		}
+		targetRate, ok := pivotRates.Values[target]
+		if !ok {
+			continue
+		}
+		rates.Values[target] = targetRate.Div(baseRate)
+		rates.Spread[target] = rates.Values[target].Mul(relativeSpread(pivotRates, target).Add(baseSpread))
+		rates.Sources[target] = min(pivotRates.SourcesFor(target), pivotRates.SourcesFor(base))
+		rates.Providers[target] = joinProviders(pivotRates.ProviderFor(target), pivotRates.ProviderFor(base))
+		legs[target] = []string{s.pivot + "/" + target, baseLeg}
+	}
+	return rates, legs, nil
+}
+
+// OnUpdate registers a callback invoked whenever a quote reaches a terminal status.
+func (s *Service) OnUpdate(fn func(q *quote.Quote)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onUpdate = append(s.onUpdate, fn)
 }
 
-func (s *Service) Stop() {
+func (s *Service) notifyUpdate(q *quote.Quote) {
+	s.mu.Lock()
+	callbacks := s.onUpdate
+	s.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(q)
+	}
+}
+
+// runJob runs fn with the service context unless the service is stopping,
+// Stop waits for it to return.
+func (s *Service) runJob(fn func(ctx context.Context)) {
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return
+	}
+	s.jobs.Add(1)
+	s.mu.Unlock()
+	defer s.jobs.Done()
+	fn(s.ctx)
+}
+
+// Stop stops scheduling new runs and waits for the running ones until ctx is done,
+// after which they are cancelled. Quotes a cancelled run did not finish keep their
+// lease and are reclaimed once it expires.
+func (s *Service) Stop(ctx context.Context) {
 	s.log.Info("Stopping cron service...")
-	if s.cron != nil {
-		s.cron.Stop()
+	s.mu.Lock()
+	s.stopping = true
+	s.mu.Unlock()
+	s.requested.stop()
+	cronDone := s.cron.Stop()
+
+	drained := make(chan struct{})
+	go func() {
+		<-cronDone.Done()
+		s.jobs.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		s.log.Info("Cron service drained")
+	case <-ctx.Done():
+		s.log.Warnf("Cron service drain timed out, cancelling running jobs")
 	}
+	s.cancel()
+	<-drained
 }
 
 func parseCurrencyPair(pair string) (base, target string, ok bool) {
@@ -116,6 +479,26 @@ func parseCurrencyPair(pair string) (base, target string, ok bool) {
 	return parts[0], parts[1], true
 }
 
+// relativeSpread returns the spread of a symbol as a fraction of its rate, zero when unknown.
+func relativeSpread(rates *exchange.Rates, symbol string) decimal.Decimal {
+	value := rates.Values[symbol]
+	if value.IsZero() {
+		return decimal.Zero
+	}
+	return rates.Spread[symbol].Div(value)
+}
+
+// joinProviders merges the comma-separated provider names of the legs of a rate.
+func joinProviders(legs ...string) string {
+	var names []string
+	for _, leg := range legs {
+		if leg != "" {
+			names = append(names, strings.Split(leg, ",")...)
+		}
+	}
+	return strings.Join(unique(names), ",")
+}
+
 func unique(items []string) []string {
 	seen := make(map[string]struct{})
 	result := make([]string, 0, len(items))
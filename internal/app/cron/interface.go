@@ -0,0 +1,27 @@
+package cron
+
+import (
+	"context"
+	"plata/internal/domain/pair"
+	"plata/internal/domain/quote"
+	"time"
+)
+
+type QuoteRepository interface {
+	ClaimDueQuotes(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int, base string) ([]*quote.Quote, error)
+	SaveLeased(ctx context.Context, quotes []*quote.Quote) error
+	ListPendingSystemCurrencies(ctx context.Context) ([]string, error)
+	Update(ctx context.Context, q *quote.Quote) error
+	SaveRun(ctx context.Context, run *quote.RunSummary) error
+	PurgeRuns(ctx context.Context, before time.Time) (int64, error)
+	PurgeOutbox(ctx context.Context, before time.Time) (int64, error)
+}
+
+type CandleRepository interface {
+	MaterializeCandles(ctx context.Context, interval time.Duration, before time.Time) (int64, error)
+}
+
+type PairCatalog interface {
+	Get(ctx context.Context, name string) (*pair.Pair, error)
+	List(ctx context.Context) ([]*pair.Pair, error)
+}
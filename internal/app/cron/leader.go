@@ -0,0 +1,131 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+type leaderJob struct {
+	name     string
+	schedule string
+	run      func(ctx context.Context)
+}
+
+func (s *Service) leaderJobs() []leaderJob {
+	var jobs []leaderJob
+	if s.purgeSchedule != "" && s.runsRetention > 0 {
+		jobs = append(jobs, leaderJob{name: "purge update runs", schedule: s.purgeSchedule, run: s.PurgeRuns})
+	}
+	if s.purgeSchedule != "" && s.outboxRetention > 0 {
+		jobs = append(jobs, leaderJob{name: "purge outbox", schedule: s.purgeSchedule, run: s.PurgeOutbox})
+	}
+	if s.prewarmSchedule != "" && s.pairs != nil {
+		jobs = append(jobs, leaderJob{name: "prewarm pairs", schedule: s.prewarmSchedule, run: func(ctx context.Context) { s.Prewarm(ctx) }})
+	}
+	if s.candles != nil && s.candlesCfg.Schedule != "" && len(s.candlesCfg.Intervals) > 0 {
+		jobs = append(jobs, leaderJob{name: "materialize candles", schedule: s.candlesCfg.Schedule, run: s.MaterializeCandles})
+	}
+	return jobs
+}
+
+func (s *Service) validateLeaderJobs() error {
+	for _, job := range s.leaderJobs() {
+		if _, err := cron.ParseStandard(job.schedule); err != nil {
+			return fmt.Errorf("invalid schedule for %s: %w", job.name, err)
+		}
+	}
+	return nil
+}
+
+// SetLeader registers the jobs that must run on a single instance when leadership
+// is gained and removes them as soon as it is lost.
+func (s *Service) SetLeader(leader bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !leader {
+		for id := range s.leaderEntries {
+			s.cron.Remove(id)
+		}
+		if len(s.leaderEntries) > 0 {
+			s.log.Infof("Leader jobs removed: %d", len(s.leaderEntries))
+		}
+		s.leaderEntries = nil
+		return
+	}
+	if len(s.leaderEntries) > 0 {
+		return
+	}
+	s.leaderEntries = make(map[cron.EntryID]string)
+	for _, job := range s.leaderJobs() {
+		id, err := s.cron.AddFunc(job.schedule, func() {
+			s.runJob(func(ctx context.Context) {
+				s.log.Infof("Running leader job: %s", job.name)
+				job.run(ctx)
+			})
+		})
+		if err != nil {
+			s.log.Errorf("Failed to register leader job %s: %v", job.name, err)
+			continue
+		}
+		s.leaderEntries[id] = job.name
+		s.log.Infof("Leader job registered: %s schedule=%s", job.name, job.schedule)
+	}
+}
+
+// LeaderJobs returns the names of the leader jobs currently scheduled, sorted.
+func (s *Service) LeaderJobs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.leaderEntries))
+	for id, name := range s.leaderEntries {
+		if s.cron.Entry(id).Valid() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetCandleRepository enables the leader job materializing closed candles, it must be
+// called before Run.
+func (s *Service) SetCandleRepository(repo CandleRepository) {
+	s.candles = repo
+}
+
+func (s *Service) MaterializeCandles(ctx context.Context) {
+	before := time.Now().UTC().Add(-s.candlesCfg.Settle)
+	for _, interval := range s.candlesCfg.Intervals {
+		stored, err := s.candles.MaterializeCandles(ctx, interval, before)
+		if err != nil {
+			s.log.Errorf("Failed to materialize %s candles: %v", interval, err)
+			continue
+		}
+		s.log.Infof("Materialized %d candles: interval=%s", stored, interval)
+	}
+}
+
+func (s *Service) PurgeRuns(ctx context.Context) {
+	before := time.Now().Add(-s.runsRetention)
+	deleted, err := s.repo.PurgeRuns(ctx, before)
+	if err != nil {
+		s.log.Errorf("Failed to purge update runs: %v", err)
+		return
+	}
+	s.log.Infof("Purged %d update runs older than %s", deleted, before.Format(time.RFC3339))
+}
+
+// PurgeOutbox deletes published quote events and finished callback deliveries once
+// they are older than the outbox retention.
+func (s *Service) PurgeOutbox(ctx context.Context) {
+	before := time.Now().Add(-s.outboxRetention)
+	deleted, err := s.repo.PurgeOutbox(ctx, before)
+	if err != nil {
+		s.log.Errorf("Failed to purge outbox: %v", err)
+		return
+	}
+	s.log.Infof("Purged %d outbox rows older than %s", deleted, before.Format(time.RFC3339))
+}
@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultDebounce = 200 * time.Millisecond
+
+// HandleQuoteRequested wakes the updater for the base currency of a freshly requested
+// pair. Bursts for the same base within the debounce window share a single run, an
+// empty payload triggers a full sweep.
+func (s *Service) HandleQuoteRequested(payload string) {
+	base := ""
+	if payload != "" {
+		b, _, ok := parseCurrencyPair(payload)
+		if !ok {
+			s.log.Warnf("Ignoring quote request notification with invalid pair: %q", payload)
+			return
+		}
+		base = b
+	}
+	s.requested.trigger(base)
+}
+
+func (s *Service) updateRequested(base string) {
+	s.runJob(func(ctx context.Context) {
+		if base == "" {
+			s.log.Info("Running quote update after missed notifications...")
+		} else {
+			s.log.Infof("Running requested quote update: base=%s", base)
+		}
+		s.updateQuotes(ctx, base)
+	})
+}
+
+// debouncer runs fire once per key at the end of a window opened by the first
+// trigger for that key.
+type debouncer struct {
+	window time.Duration
+	fire   func(key string)
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer
+	stopped bool
+}
+
+func newDebouncer(window time.Duration, fire func(key string)) *debouncer {
+	if window <= 0 {
+		window = defaultDebounce
+	}
+	return &debouncer{
+		window:  window,
+		fire:    fire,
+		pending: make(map[string]*time.Timer),
+	}
+}
+
+func (d *debouncer) trigger(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return
+	}
+	if _, ok := d.pending[key]; ok {
+		return
+	}
+	d.pending[key] = time.AfterFunc(d.window, func() {
+		d.mu.Lock()
+		delete(d.pending, key)
+		d.mu.Unlock()
+		d.fire(key)
+	})
+}
+
+func (d *debouncer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	for key, t := range d.pending {
+		t.Stop()
+		delete(d.pending, key)
+	}
+}
@@ -0,0 +1,77 @@
+package cron
+
+import (
+	"context"
+	"expvar"
+	"plata/internal/domain/quote"
+	"sync"
+	"time"
+)
+
+// resultWriteTimeout bounds each write of a quote result, which is detached from the
+// tick deadline so a rate already fetched is not lost when the tick runs out.
+const resultWriteTimeout = 5 * time.Second
+
+var (
+	metricQueueDepth     = expvar.NewInt("updater_queue_depth")
+	metricGroupsInFlight = expvar.NewInt("updater_groups_in_flight")
+	// The mean duration per base is updater_group_duration_seconds_sum / updater_group_runs.
+	metricGroupDurationSum = expvar.NewMap("updater_group_duration_seconds_sum")
+	metricGroupRuns        = expvar.NewMap("updater_group_runs")
+)
+
+// runGroups processes the groups of a batch on up to s.workers goroutines. Groups still
+// queued when ctx is done are skipped, their quotes are reclaimed once the lease expires.
+func (s *Service) runGroups(ctx context.Context, grouped map[groupKey]*quoteGroup, run *quote.RunSummary) {
+	jobs := make(chan groupKey, len(grouped))
+	for key := range grouped {
+		jobs <- key
+	}
+	close(jobs)
+	metricQueueDepth.Add(int64(len(grouped)))
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for range min(s.workers, len(grouped)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				metricQueueDepth.Add(-1)
+				group := grouped[key]
+				summary := &quote.GroupSummary{}
+				if err := ctx.Err(); err != nil {
+					summary.Skipped = len(group.quotes)
+					summary.Error = err.Error()
+				} else {
+					s.runGroup(ctx, key, group, summary)
+				}
+				mu.Lock()
+				run.Group(key.base).Merge(summary)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Service) runGroup(ctx context.Context, key groupKey, group *quoteGroup, summary *quote.GroupSummary) {
+	metricGroupsInFlight.Add(1)
+	defer metricGroupsInFlight.Add(-1)
+	start := time.Now()
+	s.updateGroup(ctx, key, group, summary)
+	elapsed := time.Since(start)
+
+	metricGroupDurationSum.AddFloat(key.base, elapsed.Seconds())
+	metricGroupRuns.Add(key.base, 1)
+	s.log.Infof("Group processed: base=%s quotes=%d duration=%s", key.base, len(group.quotes), elapsed)
+}
+
+// store writes a quote result with its own timeout, outliving the tick context.
+func (s *Service) store(ctx context.Context, q *quote.Quote) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resultWriteTimeout)
+	defer cancel()
+	return s.repo.Update(ctx, q)
+}
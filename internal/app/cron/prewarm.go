@@ -0,0 +1,77 @@
+package cron
+
+import (
+	"context"
+	"plata/internal/domain/quote"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Prewarm refreshes every enabled pair with system-originated quotes, fetched per
+// base currency group like requested updates. The quotes are created leased to this
+// instance so the request-driven updaters leave them alone. Pairs still having a
+// pending system quote, e.g. from a run interrupted by shutdown, are skipped.
+func (s *Service) Prewarm(ctx context.Context) *quote.RunSummary {
+	run := quote.NewRunSummary(uuid.NewString(), quote.OriginSystem, time.Now())
+	tickCtx := ctx
+	if s.tickDeadline > 0 {
+		var cancel context.CancelFunc
+		tickCtx, cancel = context.WithTimeout(ctx, s.tickDeadline)
+		defer cancel()
+	}
+	pairs, err := s.pairs.List(tickCtx)
+	if err != nil {
+		s.log.Errorf("Failed to list currency pairs to prewarm: %v", err)
+		run.Error = err.Error()
+		s.finishRun(ctx, run)
+		return run
+	}
+	pending, err := s.repo.ListPendingSystemCurrencies(tickCtx)
+	if err != nil {
+		s.log.Errorf("Failed to list pending prewarm quotes: %v", err)
+		run.Error = err.Error()
+		s.finishRun(ctx, run)
+		return run
+	}
+	skip := make(map[string]bool, len(pending))
+	for _, c := range pending {
+		skip[c] = true
+	}
+
+	now := time.Now()
+	leaseExpiresAt := now.Add(s.leaseTTL)
+	var quotes []*quote.Quote
+	for _, p := range pairs {
+		if !p.Enabled {
+			continue
+		}
+		if skip[p.Pair] {
+			base, _, _ := parseCurrencyPair(p.Pair)
+			run.Group(base).Skipped++
+			continue
+		}
+		quotes = append(quotes, &quote.Quote{
+			ID:             uuid.NewString(),
+			Currency:       p.Pair,
+			Scale:          int32(p.Precision),
+			Status:         quote.StatusInProgress,
+			Origin:         quote.OriginSystem,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			LeaseOwner:     s.instanceID,
+			LeaseExpiresAt: &leaseExpiresAt,
+		})
+	}
+	s.log.Infof("Prewarming %d enabled pairs: instance=%s", len(quotes), s.instanceID)
+	if len(quotes) > 0 {
+		if err = s.repo.SaveLeased(tickCtx, quotes); err != nil {
+			s.log.Errorf("Failed to save prewarm quotes: %v", err)
+			run.Error = err.Error()
+		} else {
+			s.updateQuotesBatch(tickCtx, quotes, run)
+		}
+	}
+	s.finishRun(ctx, run)
+	return run
+}
@@ -0,0 +1,116 @@
+package hub
+
+import (
+	"encoding/json"
+	"plata/internal/common/log"
+	"plata/internal/domain/quote"
+	"sync"
+	"time"
+)
+
+// dedupWindow bounds how long a published update is remembered, the same update
+// usually arrives twice: from the local updater and echoed back through NOTIFY.
+const dedupWindow = time.Minute
+
+type subscriber struct {
+	ch     chan *quote.Quote
+	filter func(q *quote.Quote) bool
+}
+
+type published struct {
+	updatedAt time.Time
+	at        time.Time
+}
+
+// Hub fans quote updates out to in-process subscribers. A subscriber whose buffer
+// is full is disconnected, its channel closed, rather than slowing down publishers.
+type Hub struct {
+	log log.Logger
+
+	mu        sync.Mutex
+	subs      map[*subscriber]struct{}
+	seen      map[string]published
+	lastPrune time.Time
+}
+
+func New(log log.Logger) *Hub {
+	return &Hub{
+		log:  log,
+		subs: make(map[*subscriber]struct{}),
+		seen: make(map[string]published),
+	}
+}
+
+// Subscribe returns a channel receiving the updates accepted by filter, all of them
+// when it is nil, and a function releasing the subscription.
+func (h *Hub) Subscribe(filter func(q *quote.Quote) bool, buffer int) (<-chan *quote.Quote, func()) {
+	if buffer < 1 {
+		buffer = 1
+	}
+	sub := &subscriber{ch: make(chan *quote.Quote, buffer), filter: filter}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if _, ok := h.subs[sub]; ok {
+				delete(h.subs, sub)
+				close(sub.ch)
+			}
+		})
+	}
+}
+
+func (h *Hub) Publish(q *quote.Quote) {
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if last, ok := h.seen[q.ID]; ok && last.updatedAt.Equal(q.UpdatedAt) {
+		return
+	}
+	h.seen[q.ID] = published{updatedAt: q.UpdatedAt, at: now}
+	h.prune(now)
+
+	update := *q
+	for sub := range h.subs {
+		if sub.filter != nil && !sub.filter(&update) {
+			continue
+		}
+		select {
+		case sub.ch <- &update:
+		default:
+			h.log.Warnf("Dropping slow quote update subscriber")
+			delete(h.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// HandleNotification publishes a quote received as JSON over Postgres NOTIFY.
+func (h *Hub) HandleNotification(payload string) {
+	if payload == "" {
+		return
+	}
+	var q quote.Quote
+	if err := json.Unmarshal([]byte(payload), &q); err != nil {
+		h.log.Warnf("Ignoring malformed quote update notification: %v", err)
+		return
+	}
+	h.Publish(&q)
+}
+
+func (h *Hub) prune(now time.Time) {
+	if now.Sub(h.lastPrune) < dedupWindow {
+		return
+	}
+	h.lastPrune = now
+	for id, p := range h.seen {
+		if now.Sub(p.at) > dedupWindow {
+			delete(h.seen, id)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLockKey       = 7401
+	defaultRenewInterval = 10 * time.Second
+)
+
+// Elector holds a session level pg advisory lock on a dedicated primary connection,
+// the instance holding the lock is the leader until its session is lost.
+type Elector struct {
+	locks    LockRepository
+	id       string
+	key      int64
+	interval time.Duration
+	enabled  bool
+	log      log.Logger
+
+	mu       sync.RWMutex
+	leader   bool
+	onChange []func(leader bool)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(cfg config.CronConfig, locks LockRepository, log log.Logger) *Elector {
+	if cfg.InstanceID == "" {
+		cfg.InstanceID = config.DefaultInstanceID()
+	}
+	if cfg.Leader.LockKey == 0 {
+		cfg.Leader.LockKey = defaultLockKey
+	}
+	if cfg.Leader.RenewInterval <= 0 {
+		cfg.Leader.RenewInterval = defaultRenewInterval
+	}
+	return &Elector{
+		locks:    locks,
+		id:       cfg.InstanceID,
+		key:      cfg.Leader.LockKey,
+		interval: cfg.Leader.RenewInterval,
+		enabled:  cfg.Leader.Enabled,
+		log:      log,
+	}
+}
+
+// OnChange registers a callback invoked whenever leadership is gained or lost.
+func (e *Elector) OnChange(fn func(leader bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onChange = append(e.onChange, fn)
+}
+
+func (e *Elector) ID() string {
+	return e.id
+}
+
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Leader returns the identity of the instance currently holding the lock, empty when there is none.
+func (e *Elector) Leader(ctx context.Context) (string, error) {
+	if !e.enabled {
+		return e.id, nil
+	}
+	return e.locks.Holder(ctx, e.key)
+}
+
+func (e *Elector) Start(ctx context.Context) {
+	if !e.enabled {
+		e.log.Infof("Leader election disabled, instance %s acts as leader", e.id)
+		e.setLeader(true)
+		return
+	}
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.done = make(chan struct{})
+	go e.loop(ctx)
+}
+
+func (e *Elector) Stop() {
+	if e.cancel == nil {
+		e.setLeader(false)
+		return
+	}
+	e.cancel()
+	<-e.done
+}
+
+func (e *Elector) loop(ctx context.Context) {
+	defer close(e.done)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		e.tick(ctx)
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+	if e.IsLeader() {
+		held, err := e.locks.Held(ctx, e.key)
+		if err == nil && !held {
+			err = errors.New("advisory lock is no longer held")
+		}
+		if err != nil {
+			e.log.Errorf("Leadership lost: instance=%s error=%v", e.id, err)
+			e.locks.Close()
+			e.setLeader(false)
+		}
+		return
+	}
+	acquired, err := e.locks.TryLock(ctx, e.key, e.id)
+	if err != nil {
+		e.log.Warnf("Failed to acquire leadership: instance=%s error=%v", e.id, err)
+		return
+	}
+	if acquired {
+		e.log.Infof("Leadership acquired: instance=%s", e.id)
+		e.setLeader(true)
+	}
+}
+
+func (e *Elector) release() {
+	if !e.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+	defer cancel()
+	if err := e.locks.Unlock(ctx, e.key); err != nil {
+		e.log.Warnf("Failed to release leadership: instance=%s error=%v", e.id, err)
+	}
+	e.setLeader(false)
+	e.log.Infof("Leadership released: instance=%s", e.id)
+}
+
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	changed := e.leader != leader
+	e.leader = leader
+	callbacks := append([]func(bool){}, e.onChange...)
+	e.mu.Unlock()
+	if !changed {
+		return
+	}
+	for _, fn := range callbacks {
+		fn(leader)
+	}
+}
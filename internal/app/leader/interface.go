@@ -0,0 +1,18 @@
+package leader
+
+import "context"
+
+type StatusClient interface {
+	ID() string
+	IsLeader() bool
+	Leader(ctx context.Context) (string, error)
+}
+
+// LockRepository holds the leader lock on a dedicated database session.
+type LockRepository interface {
+	TryLock(ctx context.Context, key int64, name string) (bool, error)
+	Held(ctx context.Context, key int64) (bool, error)
+	Unlock(ctx context.Context, key int64) error
+	Close()
+	Holder(ctx context.Context, key int64) (string, error)
+}
@@ -0,0 +1,18 @@
+package outbox
+
+import (
+	"context"
+	"plata/internal/domain/quote"
+	"time"
+)
+
+type EventRepository interface {
+	PublishPending(ctx context.Context, owner string, lease time.Duration, limit int, publish func(ctx context.Context, events []*quote.Event) error) (int, error)
+}
+
+// EventPublisher hands quote events to other systems. Publish must either accept the
+// whole batch or fail, delivery is at least once so consumers deduplicate by event ID.
+type EventPublisher interface {
+	Publish(ctx context.Context, events []*quote.Event) error
+	Close() error
+}
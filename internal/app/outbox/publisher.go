@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"plata/internal/config"
+	"plata/internal/domain/quote"
+	"sync"
+	"time"
+)
+
+const (
+	PublisherNDJSON = "ndjson"
+	PublisherHTTP   = "http"
+
+	defaultPublishTimeout = 5 * time.Second
+)
+
+var ErrUnknownPublisher = errors.New("unknown event publisher")
+
+func NewPublisher(cfg config.EventsConfig) (EventPublisher, error) {
+	switch cfg.Publisher {
+	case PublisherNDJSON, "":
+		return NewNDJSONPublisher(cfg.Path)
+	case PublisherHTTP:
+		return NewHTTPPublisher(cfg.URL, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPublisher, cfg.Publisher)
+	}
+}
+
+func encodeNDJSON(events []*quote.Event) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return nil, fmt.Errorf("failed to encode event %s: %w", e.ID, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// NDJSONPublisher appends events, one JSON document per line, to a local file.
+type NDJSONPublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewNDJSONPublisher(path string) (*NDJSONPublisher, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create event log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	return &NDJSONPublisher{file: f}, nil
+}
+
+func (p *NDJSONPublisher) Publish(_ context.Context, events []*quote.Event) error {
+	data, err := encodeNDJSON(events)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err = p.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write event log: %w", err)
+	}
+	if err = p.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync event log: %w", err)
+	}
+	return nil
+}
+
+func (p *NDJSONPublisher) Close() error {
+	return p.file.Close()
+}
+
+// HTTPPublisher posts each batch of events as an NDJSON body to a sink URL,
+// any non-2xx response fails the batch.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(url string, timeout time.Duration) *HTTPPublisher {
+	if timeout <= 0 {
+		timeout = defaultPublishTimeout
+	}
+	return &HTTPPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, events []*quote.Event) error {
+	data, err := encodeNDJSON(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *HTTPPublisher) Close() error {
+	return nil
+}
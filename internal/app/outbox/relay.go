@@ -0,0 +1,120 @@
+package outbox
+
+import (
+	"context"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"plata/internal/domain/quote"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultLeaseTTL     = time.Minute
+)
+
+// Relay moves quote events from the outbox to the publisher. An event is marked
+// published only after the publisher accepted it, so delivery is at least once.
+type Relay struct {
+	repo      EventRepository
+	publisher EventPublisher
+	interval  time.Duration
+	batchSize int
+	leaseTTL  time.Duration
+	owner     string
+	log       log.Logger
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(cfg config.EventsConfig, instanceID string, repo EventRepository, publisher EventPublisher, log log.Logger) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = defaultLeaseTTL
+	}
+	if instanceID == "" {
+		instanceID = config.DefaultInstanceID()
+	}
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		interval:  cfg.PollInterval,
+		batchSize: cfg.BatchSize,
+		leaseTTL:  cfg.LeaseTTL,
+		owner:     instanceID,
+		log:       log,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+func (r *Relay) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go r.loop(ctx)
+}
+
+// Stop waits for the batch in flight and closes the publisher.
+func (r *Relay) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+	if err := r.publisher.Close(); err != nil {
+		r.log.Errorf("Failed to close event publisher: %v", err)
+	}
+}
+
+// Wake triggers a relay pass without waiting for the next poll.
+func (r *Relay) Wake() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Relay) loop(ctx context.Context) {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.RelayPending(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// RelayPending publishes pending events batch by batch until none are left or a
+// publish fails, and returns how many were published.
+func (r *Relay) RelayPending(ctx context.Context) int {
+	var published int
+	for ctx.Err() == nil {
+		n, err := r.repo.PublishPending(ctx, r.owner, r.leaseTTL, r.batchSize, func(ctx context.Context, events []*quote.Event) error {
+			return r.publisher.Publish(ctx, events)
+		})
+		if err != nil {
+			r.log.Errorf("Failed to relay quote events: %v", err)
+			break
+		}
+		published += n
+		if n < r.batchSize {
+			break
+		}
+	}
+	if published > 0 {
+		r.log.Infof("Relayed %d quote events", published)
+	}
+	return published
+}
@@ -31,16 +31,20 @@ func (pg *PgDB) Replica() *sqlx.DB {
 	return pg.prime
 }
 
-func NewPostgresDB(cfg config.PostgresConfig, logger log.Logger) (*PgDB, error) {
-	dsnPrimary := fmt.Sprintf(
+func dsn(cfg config.PostgresConfig, host string) string {
+	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.HostPrimary,
+		host,
 		cfg.Port,
 		cfg.Username,
 		cfg.Password,
 		cfg.Database,
 		cfg.SSLMode,
 	)
+}
+
+func NewPostgresDB(cfg config.PostgresConfig, logger log.Logger) (*PgDB, error) {
+	dsnPrimary := dsn(cfg, cfg.HostPrimary)
 
 	prime, err := sqlx.Connect("postgres", dsnPrimary)
 	if err != nil {
@@ -60,15 +64,7 @@ func NewPostgresDB(cfg config.PostgresConfig, logger log.Logger) (*PgDB, error)
 
 	var replica *sqlx.DB
 	if cfg.HostReplica != "" {
-		dsnReplica := fmt.Sprintf(
-			"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-			cfg.HostReplica,
-			cfg.Port,
-			cfg.Username,
-			cfg.Password,
-			cfg.Database,
-			cfg.SSLMode,
-		)
+		dsnReplica := dsn(cfg, cfg.HostReplica)
 
 		replica, err = sqlx.Connect("postgres", dsnReplica)
 		if err != nil {
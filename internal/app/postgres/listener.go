@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+	listenerPingInterval = 90 * time.Second
+)
+
+// Handler receives the payload of a notification. An empty payload is delivered
+// after the connection was re-established, notifications may have been missed
+// in between.
+type Handler func(payload string)
+
+// Listener dispatches Postgres NOTIFY messages from the primary to registered handlers
+// over a dedicated connection that reconnects on its own.
+type Listener struct {
+	listener *pq.Listener
+	log      log.Logger
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewListener(cfg config.PostgresConfig, log log.Logger) *Listener {
+	l := &Listener{
+		log:      log,
+		handlers: make(map[string][]Handler),
+	}
+	l.listener = pq.NewListener(dsn(cfg, cfg.HostPrimary), listenerMinReconnect, listenerMaxReconnect, l.onEvent)
+	return l
+}
+
+func (l *Listener) Listen(channel string, handler Handler) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.handlers[channel]; !ok {
+		if err := l.listener.Listen(channel); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", channel, err)
+		}
+	}
+	l.handlers[channel] = append(l.handlers[channel], handler)
+	return nil
+}
+
+func (l *Listener) Start(ctx context.Context) {
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.done = make(chan struct{})
+	go l.loop(ctx)
+}
+
+func (l *Listener) Stop() {
+	if l.cancel == nil {
+		return
+	}
+	l.cancel()
+	<-l.done
+	if err := l.listener.Close(); err != nil {
+		l.log.Errorf("Failed to close notification listener: %v", err)
+	}
+}
+
+func (l *Listener) loop(ctx context.Context) {
+	defer close(l.done)
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-l.listener.Notify:
+			if n == nil {
+				l.resync()
+				continue
+			}
+			l.dispatch(n.Channel, n.Extra)
+		case <-ticker.C:
+			go func() {
+				if err := l.listener.Ping(); err != nil {
+					l.log.Warnf("Notification listener ping failed: %v", err)
+				}
+			}()
+		}
+	}
+}
+
+func (l *Listener) dispatch(channel, payload string) {
+	l.mu.RLock()
+	handlers := l.handlers[channel]
+	l.mu.RUnlock()
+	for _, h := range handlers {
+		h(payload)
+	}
+}
+
+func (l *Listener) resync() {
+	l.mu.RLock()
+	channels := make([]string, 0, len(l.handlers))
+	for channel := range l.handlers {
+		channels = append(channels, channel)
+	}
+	l.mu.RUnlock()
+	for _, channel := range channels {
+		l.dispatch(channel, "")
+	}
+}
+
+func (l *Listener) onEvent(ev pq.ListenerEventType, err error) {
+	switch ev {
+	case pq.ListenerEventConnected:
+		l.log.Infof("Notification listener connected")
+	case pq.ListenerEventDisconnected:
+		l.log.Warnf("Notification listener disconnected: %v", err)
+	case pq.ListenerEventReconnected:
+		l.log.Infof("Notification listener reconnected")
+	case pq.ListenerEventConnectionAttemptFailed:
+		l.log.Warnf("Notification listener connection attempt failed: %v", err)
+	}
+}
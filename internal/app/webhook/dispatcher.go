@@ -0,0 +1,228 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"plata/internal/common/backoff"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"plata/internal/domain/webhook"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultTimeout      = 5 * time.Second
+	defaultMaxAttempts  = 8
+	defaultBatchSize    = 10
+	defaultLeaseTTL     = time.Minute
+	maxResponseBody     = 64 << 10
+)
+
+// Dispatcher delivers the callbacks stored in the webhook outbox. Deliveries are
+// claimed with a lease, so every instance can run one, and retried with backoff
+// until they succeed or run out of attempts.
+type Dispatcher struct {
+	repo        DeliveryRepository
+	client      *http.Client
+	secret      []byte
+	retry       backoff.Backoff
+	maxAttempts int
+	batchSize   int
+	leaseTTL    time.Duration
+	interval    time.Duration
+	instanceID  string
+	log         log.Logger
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(cfg config.WebhookConfig, instanceID string, repo DeliveryRepository, log log.Logger) *Dispatcher {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = defaultLeaseTTL
+	}
+	if instanceID == "" {
+		instanceID = config.DefaultInstanceID()
+	}
+	return &Dispatcher{
+		repo: repo,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// only the allow-listed host is called, a redirect counts as a failed attempt
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		secret:      []byte(cfg.Secret),
+		retry:       backoff.New(cfg.Retry),
+		maxAttempts: cfg.MaxAttempts,
+		batchSize:   cfg.BatchSize,
+		leaseTTL:    cfg.LeaseTTL,
+		interval:    cfg.PollInterval,
+		instanceID:  instanceID,
+		log:         log,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.done = make(chan struct{})
+	go d.loop(ctx)
+}
+
+func (d *Dispatcher) Stop() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	<-d.done
+}
+
+// Wake triggers a dispatch without waiting for the next poll.
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) loop(ctx context.Context) {
+	defer close(d.done)
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		d.DispatchDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// DispatchDue delivers due callbacks until none are left and returns how many
+// delivery attempts were made.
+func (d *Dispatcher) DispatchDue(ctx context.Context) int {
+	var attempted int
+	for ctx.Err() == nil {
+		deliveries, err := d.repo.ClaimDue(ctx, d.instanceID, time.Now(), d.leaseTTL, d.batchSize)
+		if err != nil {
+			d.log.Errorf("Failed to claim due callbacks: %v", err)
+			break
+		}
+		for _, del := range deliveries {
+			if ctx.Err() != nil {
+				break
+			}
+			d.deliver(ctx, del)
+			attempted++
+		}
+		if len(deliveries) < d.batchSize {
+			break
+		}
+	}
+	return attempted
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, del *webhook.Delivery) {
+	start := time.Now()
+	status, err := d.post(ctx, del, start)
+	if ctx.Err() != nil {
+		// Shutting down: the lease expires and the callback is retried later.
+		return
+	}
+	now := time.Now()
+	del.Attempts++
+	attempt := &webhook.Attempt{
+		DeliveryID:  del.ID,
+		Attempt:     del.Attempts,
+		StatusCode:  status,
+		DurationMS:  now.Sub(start).Milliseconds(),
+		AttemptedAt: start,
+	}
+	switch {
+	case err == nil:
+		del.Status = webhook.StatusDelivered
+		del.DeliveredAt = &now
+		del.LastError = ""
+		del.NextAttemptAt = nil
+	case del.Attempts >= d.maxAttempts:
+		attempt.Error = err.Error()
+		del.Status = webhook.StatusFailed
+		del.LastError = err.Error()
+		del.NextAttemptAt = nil
+	default:
+		attempt.Error = err.Error()
+		next := now.Add(d.retry.Delay(del.Attempts))
+		del.LastError = err.Error()
+		del.NextAttemptAt = &next
+	}
+
+	if err := d.repo.Record(ctx, del, attempt); err != nil {
+		d.log.Errorf("Failed to record callback attempt: id=%s quote=%s: %v", del.ID, del.QuoteID, err)
+		return
+	}
+	switch del.Status {
+	case webhook.StatusDelivered:
+		d.log.Infof("Callback delivered: id=%s quote=%s host=%s attempts=%d", del.ID, del.QuoteID, del.Host(), del.Attempts)
+	case webhook.StatusFailed:
+		d.log.Warnf("Callback failed: id=%s quote=%s host=%s attempts=%d error=%s", del.ID, del.QuoteID, del.Host(), del.Attempts, del.LastError)
+	default:
+		d.log.Warnf("Callback retry scheduled: id=%s quote=%s attempts=%d next_attempt_at=%s error=%s",
+			del.ID, del.QuoteID, del.Attempts, del.NextAttemptAt.Format(time.RFC3339), del.LastError,
+		)
+	}
+}
+
+// post sends the signed payload and returns the response status, any non-2xx status is an error.
+func (d *Dispatcher) post(ctx context.Context, del *webhook.Delivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.URL, strings.NewReader(del.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request for %s", del.Host())
+	}
+	ts := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(d.secret, ts, []byte(del.Payload)))
+	req.Header.Set(webhook.HeaderDelivery, del.ID)
+	req.Header.Set(webhook.HeaderEvent, del.Event)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		// The url.Error quotes the full callback URL, keep the host only since the
+		// error is logged and returned in the delivery log.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return 0, fmt.Errorf("%s %s: %w", urlErr.Op, del.Host(), urlErr.Err)
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
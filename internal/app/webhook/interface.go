@@ -0,0 +1,12 @@
+package webhook
+
+import (
+	"context"
+	"plata/internal/domain/webhook"
+	"time"
+)
+
+type DeliveryRepository interface {
+	ClaimDue(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error)
+	Record(ctx context.Context, d *webhook.Delivery, attempt *webhook.Attempt) error
+}
@@ -9,17 +9,23 @@ import (
 	"plata/internal/common/log"
 	"plata/internal/config"
 	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
+const baseRestrictedError = "base_currency_access_restricted"
+
 type Service struct {
+	Name   string
 	URL    string
 	APIKey string
 	client *http.Client
 	log    log.Logger
 }
 
-func New(cfg config.ExchangeConfig, log log.Logger) *Service {
+func New(cfg config.ProviderConfig, log log.Logger) *Service {
 	return &Service{
+		Name:   cfg.Name,
 		APIKey: cfg.APIKey,
 		URL:    cfg.URL,
 		client: &http.Client{
@@ -29,7 +35,7 @@ func New(cfg config.ExchangeConfig, log log.Logger) *Service {
 	}
 }
 
-func (s *Service) FetchRates(ctx context.Context, base string, targets []string) (map[string]float64, error) {
+func (s *Service) FetchRates(ctx context.Context, base string, targets []string) (*Rates, error) {
 	urlF, err := buildURL(s.URL, map[string]string{
 		"access_key": s.APIKey,
 		"base":       base,
@@ -51,24 +57,41 @@ func (s *Service) FetchRates(ctx context.Context, base string, targets []string)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		s.log.Errorf("API error: status=%s code=%d", resp.Status, resp.StatusCode)
+		s.log.Errorf("API error: provider=%s status=%s code=%d", s.Name, resp.Status, resp.StatusCode)
 		return nil, fmt.Errorf("unexpected API error: %s", resp.Status)
 	}
 
 	var result struct {
-		Success bool               `json:"success"`
-		Rates   map[string]float64 `json:"rates"`
+		Success bool                   `json:"success"`
+		Rates   map[string]json.Number `json:"rates"`
+		Error   struct {
+			Code int    `json:"code"`
+			Type string `json:"type"`
+		} `json:"error"`
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err = decoder.Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode response body: %w", err)
 	}
 
 	if !result.Success {
-		s.log.Error("API responded with success = false")
+		s.log.Errorf("API responded with success = false: provider=%s code=%d type=%s", s.Name, result.Error.Code, result.Error.Type)
+		if result.Error.Type == baseRestrictedError {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedBase, base)
+		}
 		return nil, fmt.Errorf("API response was not successful")
 	}
-	return result.Rates, nil
+	values := make(map[string]decimal.Decimal, len(result.Rates))
+	for symbol, number := range result.Rates {
+		rate, err := decimal.NewFromString(number.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate for %s: %w", symbol, err)
+		}
+		values[symbol] = rate
+	}
+	return &Rates{Provider: s.Name, Values: values}, nil
 }
 
 func buildURL(base string, params map[string]string) (string, error) {
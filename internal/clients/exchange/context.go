@@ -0,0 +1,28 @@
+package exchange
+
+import "context"
+
+type providerKey struct{}
+
+// WithProvider restricts composite fetchers to the named provider for this call.
+func WithProvider(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, providerKey{}, name)
+}
+
+func providerFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(providerKey{}).(string)
+	return name
+}
+
+func selectProviders(ctx context.Context, providers []Provider) []Provider {
+	name := providerFromContext(ctx)
+	if name == "" {
+		return providers
+	}
+	for _, p := range providers {
+		if p.Name == name {
+			return []Provider{p}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package exchange
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"plata/internal/common/log"
+	"plata/internal/config"
+
+	"github.com/shopspring/decimal"
+)
+
+const ecbBase = "EUR"
+
+// ECB reads the European Central Bank reference rates feed (daily, 90-day or historical XML).
+type ECB struct {
+	Name   string
+	URL    string
+	client *http.Client
+	log    log.Logger
+}
+
+type ecbEnvelope struct {
+	Days []ecbDay `xml:"Cube>Cube"`
+}
+
+type ecbDay struct {
+	Time  string    `xml:"time,attr"`
+	Rates []ecbRate `xml:"Cube"`
+}
+
+type ecbRate struct {
+	Currency string `xml:"currency,attr"`
+	Rate     string `xml:"rate,attr"`
+}
+
+func NewECB(cfg config.ProviderConfig, log log.Logger) *ECB {
+	return &ECB{
+		Name: cfg.Name,
+		URL:  cfg.URL,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		log: log,
+	}
+}
+
+func (e *ECB) FetchRates(ctx context.Context, base string, symbols []string) (*Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %v", err)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		e.log.Errorf("ECB feed error: provider=%s status=%s code=%d", e.Name, resp.Status, resp.StatusCode)
+		return nil, fmt.Errorf("unexpected ECB feed error: %s", resp.Status)
+	}
+
+	var envelope ecbEnvelope
+	if err = xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode ECB feed: %w", err)
+	}
+	day, ok := envelope.latest()
+	if !ok {
+		return nil, fmt.Errorf("ECB feed contains no rates")
+	}
+
+	eurRates := make(map[string]decimal.Decimal, len(day.Rates)+1)
+	eurRates[ecbBase] = decimal.NewFromInt(1)
+	for _, r := range day.Rates {
+		rate, err := decimal.NewFromString(r.Rate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECB rate for %s: %w", r.Currency, err)
+		}
+		eurRates[r.Currency] = rate
+	}
+	baseRate, ok := eurRates[base]
+	if !ok || baseRate.IsZero() {
+		return nil, fmt.Errorf("%w: %s is not published by ECB", ErrUnsupportedBase, base)
+	}
+
+	values := make(map[string]decimal.Decimal, len(symbols))
+	for _, symbol := range symbols {
+		rate, ok := eurRates[symbol]
+		if !ok {
+			continue
+		}
+		if base == ecbBase {
+			values[symbol] = rate
+			continue
+		}
+		values[symbol] = rate.Div(baseRate)
+	}
+	return &Rates{Provider: e.Name, Values: values}, nil
+}
+
+// latest picks the most recent day, the 90-day and historical feeds carry many of them.
+func (env *ecbEnvelope) latest() (ecbDay, bool) {
+	var (
+		found ecbDay
+		ok    bool
+	)
+	for _, day := range env.Days {
+		if len(day.Rates) == 0 {
+			continue
+		}
+		if !ok || day.Time > found.Time {
+			found, ok = day, true
+		}
+	}
+	return found, ok
+}
@@ -0,0 +1,139 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"plata/internal/common/log"
+	"plata/internal/config"
+)
+
+const (
+	StrategyFailover = "failover"
+	StrategyMedian   = "median"
+
+	ProviderExchangeRatesAPI = "exchangeratesapi"
+	ProviderECB              = "ecb"
+)
+
+var (
+	ErrNoProviders         = errors.New("no exchange providers configured")
+	ErrUnknownStrategy     = errors.New("unknown exchange strategy")
+	ErrUnknownProviderType = errors.New("unknown exchange provider type")
+	ErrUnsupportedBase     = errors.New("base currency is not supported by provider")
+)
+
+type Provider struct {
+	Name    string
+	Fetcher ExternalRateFetcher
+}
+
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("provider %s: %v", e.Provider, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+type Failover struct {
+	providers []Provider
+	log       log.Logger
+}
+
+func NewFailover(providers []Provider, log log.Logger) *Failover {
+	return &Failover{
+		providers: providers,
+		log:       log,
+	}
+}
+
+func NewFromConfig(cfg config.ExchangeConfig, log log.Logger) (ExternalRateFetcher, error) {
+	if len(cfg.Providers) == 0 {
+		return nil, ErrNoProviders
+	}
+	names := ProviderNames(cfg)
+	providers := make([]Provider, 0, len(cfg.Providers))
+	for i, pc := range cfg.Providers {
+		pc.Name = names[i]
+		if pc.Timeout == 0 {
+			pc.Timeout = cfg.Timeout
+		}
+		fetcher, err := newProvider(pc, log)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, Provider{Name: pc.Name, Fetcher: fetcher})
+	}
+	switch cfg.Strategy {
+	case "", StrategyFailover:
+		return NewFailover(providers, log), nil
+	case StrategyMedian:
+		return NewMedian(providers, cfg.MaxDeviation, cfg.MinSources, log), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, cfg.Strategy)
+	}
+}
+
+// ProviderNames returns the names of the configured providers, unnamed ones numbered
+// by position.
+func ProviderNames(cfg config.ExchangeConfig) []string {
+	names := make([]string, len(cfg.Providers))
+	for i, pc := range cfg.Providers {
+		names[i] = pc.Name
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("provider-%d", i+1)
+		}
+	}
+	return names
+}
+
+func newProvider(cfg config.ProviderConfig, log log.Logger) (ExternalRateFetcher, error) {
+	switch cfg.Type {
+	case "", ProviderExchangeRatesAPI:
+		return New(cfg, log), nil
+	case ProviderECB:
+		return NewECB(cfg, log), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProviderType, cfg.Type)
+	}
+}
+
+func (f *Failover) FetchRates(ctx context.Context, base string, symbols []string) (*Rates, error) {
+	providers := selectProviders(ctx, f.providers)
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+	var skipped []*ProviderError
+	for _, p := range providers {
+		if err := ctx.Err(); err != nil {
+			skipped = append(skipped, &ProviderError{Provider: p.Name, Err: err})
+			break
+		}
+		rates, err := p.Fetcher.FetchRates(ctx, base, symbols)
+		if err != nil {
+			f.log.Warnf("Provider %s failed for base=%s: %v", p.Name, base, err)
+			skipped = append(skipped, &ProviderError{Provider: p.Name, Err: err})
+			continue
+		}
+		if rates.Provider == "" {
+			rates.Provider = p.Name
+		}
+		rates.Skipped = append(rates.Skipped, skipped...)
+		return rates, nil
+	}
+	return nil, allFailed(skipped)
+}
+
+func allFailed(skipped []*ProviderError) error {
+	errs := make([]error, len(skipped))
+	for i, e := range skipped {
+		errs[i] = e
+	}
+	return fmt.Errorf("all providers failed: %w", errors.Join(errs...))
+}
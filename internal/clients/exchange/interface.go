@@ -1,7 +1,35 @@
 package exchange
 
-import "context"
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+type Rates struct {
+	Provider string
+	// Providers names the providers behind each symbol when they differ from Provider.
+	Providers map[string]string
+	Values    map[string]decimal.Decimal
+	Sources   map[string]int
+	Spread    map[string]decimal.Decimal
+	Skipped   []*ProviderError
+}
+
+func (r *Rates) ProviderFor(symbol string) string {
+	if p, ok := r.Providers[symbol]; ok {
+		return p
+	}
+	return r.Provider
+}
+
+func (r *Rates) SourcesFor(symbol string) int {
+	if n, ok := r.Sources[symbol]; ok {
+		return n
+	}
+	return 1
+}
 
 type ExternalRateFetcher interface {
-	FetchRates(ctx context.Context, base string, symbols []string) (map[string]float64, error)
+	FetchRates(ctx context.Context, base string, symbols []string) (*Rates, error)
 }
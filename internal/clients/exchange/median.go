@@ -0,0 +1,157 @@
+package exchange
+
+import (
+	"context"
+	"plata/internal/common/log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+var two = decimal.NewFromInt(2)
+
+type sample struct {
+	provider string
+	value    decimal.Decimal
+}
+
+type Median struct {
+	providers    []Provider
+	maxDeviation decimal.Decimal
+	minSources   int
+	log          log.Logger
+}
+
+func NewMedian(providers []Provider, maxDeviation float64, minSources int, log log.Logger) *Median {
+	if minSources < 1 {
+		minSources = 1
+	}
+	return &Median{
+		providers:    providers,
+		maxDeviation: decimal.NewFromFloat(maxDeviation),
+		minSources:   minSources,
+		log:          log,
+	}
+}
+
+func (m *Median) FetchRates(ctx context.Context, base string, symbols []string) (*Rates, error) {
+	providers := selectProviders(ctx, m.providers)
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+	type result struct {
+		rates *Rates
+		err   error
+	}
+	results := make([]result, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rates, err := p.Fetcher.FetchRates(ctx, base, symbols)
+			results[i] = result{rates: rates, err: err}
+		}()
+	}
+	wg.Wait()
+
+	var (
+		answered []string
+		skipped  []*ProviderError
+		samples  = make(map[string][]sample, len(symbols))
+	)
+	for i, res := range results {
+		name := providers[i].Name
+		if res.err != nil {
+			m.log.Warnf("Provider %s failed for base=%s: %v", name, base, res.err)
+			skipped = append(skipped, &ProviderError{Provider: name, Err: res.err})
+			continue
+		}
+		answered = append(answered, name)
+		for symbol, value := range res.rates.Values {
+			samples[symbol] = append(samples[symbol], sample{provider: name, value: value})
+		}
+	}
+	if len(answered) == 0 {
+		return nil, allFailed(skipped)
+	}
+
+	out := &Rates{
+		Providers: make(map[string]string, len(symbols)),
+		Values:    make(map[string]decimal.Decimal, len(symbols)),
+		Sources:   make(map[string]int, len(symbols)),
+		Spread:    make(map[string]decimal.Decimal, len(symbols)),
+		Skipped:   skipped,
+	}
+	// Only providers whose rate made it into a median are credited, outliers are not.
+	contributed := make(map[string]bool, len(answered))
+	for _, symbol := range symbols {
+		if len(samples[symbol]) == 0 {
+			continue
+		}
+		agreeing := m.withoutOutliers(samples[symbol])
+		if len(agreeing) < m.minSources {
+			m.log.Warnf("Not enough agreeing sources for %s/%s: got %d, need %d", base, symbol, len(agreeing), m.minSources)
+			continue
+		}
+		values := make([]decimal.Decimal, len(agreeing))
+		names := make(map[string]bool, len(agreeing))
+		for i, s := range agreeing {
+			values[i] = s.value
+			names[s.provider] = true
+			contributed[s.provider] = true
+		}
+		out.Values[symbol] = median(values)
+		out.Sources[symbol] = len(values)
+		out.Spread[symbol] = values[len(values)-1].Sub(values[0])
+		out.Providers[symbol] = joinNames(answered, names)
+	}
+	out.Provider = joinNames(answered, contributed)
+	return out, nil
+}
+
+// joinNames joins the names of ordered found in set, keeping their order.
+func joinNames(ordered []string, set map[string]bool) string {
+	var names []string
+	for _, name := range ordered {
+		if set[name] {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// withoutOutliers returns the samples sorted by value whose relative distance from the
+// median fits maxDeviation.
+func (m *Median) withoutOutliers(samples []sample) []sample {
+	sorted := append([]sample(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].value.LessThan(sorted[j].value)
+	})
+	if !m.maxDeviation.IsPositive() {
+		return sorted
+	}
+	values := make([]decimal.Decimal, len(sorted))
+	for i, s := range sorted {
+		values[i] = s.value
+	}
+	mid := median(values)
+	agreeing := sorted[:0:0]
+	for _, s := range sorted {
+		if !mid.IsZero() && s.value.Sub(mid).Abs().Div(mid).GreaterThan(m.maxDeviation) {
+			continue
+		}
+		agreeing = append(agreeing, s)
+	}
+	return agreeing
+}
+
+func median(sorted []decimal.Decimal) decimal.Decimal {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return sorted[n/2-1].Add(sorted[n/2]).Div(two)
+}
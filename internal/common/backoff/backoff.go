@@ -0,0 +1,45 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"plata/internal/config"
+	"time"
+)
+
+const (
+	defaultRetryBaseDelay = 30 * time.Second
+	defaultRetryMaxDelay  = 10 * time.Minute
+)
+
+// Backoff computes exponential retry delays with jitter.
+type Backoff struct {
+	base   time.Duration
+	max    time.Duration
+	jitter float64
+}
+
+func New(cfg config.RetryConfig) Backoff {
+	b := Backoff{base: cfg.BaseDelay, max: cfg.MaxDelay, jitter: cfg.Jitter}
+	if b.base <= 0 {
+		b.base = defaultRetryBaseDelay
+	}
+	if b.max < b.base {
+		b.max = max(defaultRetryMaxDelay, b.base)
+	}
+	b.jitter = min(max(b.jitter, 0), 1)
+	return b
+}
+
+// Delay returns base * 2^(attempts-1) capped by max and spread by +/- jitter.
+func (b Backoff) Delay(attempts int) time.Duration {
+	d := b.base
+	for i := 1; i < attempts && d < b.max; i++ {
+		d *= 2
+	}
+	d = min(d, b.max)
+	if b.jitter > 0 {
+		spread := float64(d) * b.jitter
+		d += time.Duration(spread * (2*rand.Float64() - 1))
+	}
+	return d
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"os"
@@ -20,20 +21,146 @@ type PostgresConfig struct {
 	SSLMode         string `yaml:"sslmode"`
 }
 
-type ExchangeConfig struct {
-	Timeout time.Duration `yaml:"timeout"`
-	APIKey  string        `yaml:"api_key"`
+type ProviderConfig struct {
+	Name    string        `yaml:"name"`
+	Type    string        `yaml:"type"`
 	URL     string        `yaml:"url"`
+	APIKey  string        `yaml:"api_key"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type ExchangeConfig struct {
+	Timeout      time.Duration    `yaml:"timeout"`
+	Strategy     string           `yaml:"strategy"`
+	MaxDeviation float64          `yaml:"max_deviation"`
+	MinSources   int              `yaml:"min_sources"`
+	Providers    []ProviderConfig `yaml:"providers"`
+}
+
+type TriangulationConfig struct {
+	Pivot            string   `yaml:"pivot"`
+	UnsupportedBases []string `yaml:"unsupported_bases"`
+}
+
+type RetryConfig struct {
+	BaseDelay time.Duration `yaml:"base_delay"`
+	MaxDelay  time.Duration `yaml:"max_delay"`
+	Jitter    float64       `yaml:"jitter"`
+}
+
+type LeaderConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	LockKey       int64         `yaml:"lock_key"`
+	RenewInterval time.Duration `yaml:"renew_interval"`
+}
+
+type NotifyConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Debounce time.Duration `yaml:"debounce"`
+}
+
+type CandlesConfig struct {
+	Schedule  string          `yaml:"schedule"`
+	Intervals []time.Duration `yaml:"intervals"`
+	Settle    time.Duration   `yaml:"settle"`
 }
 
 type CronConfig struct {
-	Schedule string `yaml:"schedule"`
+	Schedule        string              `yaml:"schedule"`
+	MaxAttempts     int                 `yaml:"max_attempts"`
+	MaxAge          time.Duration       `yaml:"max_age"`
+	Retry           RetryConfig         `yaml:"retry"`
+	InstanceID      string              `yaml:"instance_id"`
+	BatchSize       int                 `yaml:"batch_size"`
+	LeaseTTL        time.Duration       `yaml:"lease_ttl"`
+	Workers         int                 `yaml:"workers"`
+	TickDeadline    time.Duration       `yaml:"tick_deadline"`
+	DrainTimeout    time.Duration       `yaml:"drain_timeout"`
+	Leader          LeaderConfig        `yaml:"leader"`
+	PurgeSchedule   string              `yaml:"purge_schedule"`
+	PrewarmSchedule string              `yaml:"prewarm_schedule"`
+	RunsRetention   time.Duration       `yaml:"runs_retention"`
+	OutboxRetention time.Duration       `yaml:"outbox_retention"`
+	Notify          NotifyConfig        `yaml:"notify"`
+	Candles         CandlesConfig       `yaml:"candles"`
+	Triangulation   TriangulationConfig `yaml:"triangulation"`
+}
+
+type ServerConfig struct {
+	Addr            string        `yaml:"addr"`
+	MaxWait         time.Duration `yaml:"max_wait"`
+	StreamHeartbeat time.Duration `yaml:"stream_heartbeat"`
+	StreamBuffer    int           `yaml:"stream_buffer"`
+	LatestRefresh   bool          `yaml:"latest_refresh"`
+}
+
+type WebhookConfig struct {
+	Secret       string        `yaml:"secret"`
+	AllowedHosts []string      `yaml:"allowed_hosts"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	Timeout      time.Duration `yaml:"timeout"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+	BatchSize    int           `yaml:"batch_size"`
+	LeaseTTL     time.Duration `yaml:"lease_ttl"`
+	Retry        RetryConfig   `yaml:"retry"`
+}
+
+type EventsConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	Publisher    string        `yaml:"publisher"`
+	Path         string        `yaml:"path"`
+	URL          string        `yaml:"url"`
+	Timeout      time.Duration `yaml:"timeout"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	LeaseTTL     time.Duration `yaml:"lease_ttl"`
+}
+
+type ConvertConfig struct {
+	MaxStaleness time.Duration `yaml:"max_staleness"`
+	Pivots       []string      `yaml:"pivots"`
+	Rounding     string        `yaml:"rounding"`
+}
+
+type LockConfig struct {
+	DefaultTTL   time.Duration `yaml:"default_ttl"`
+	MaxTTL       time.Duration `yaml:"max_ttl"`
+	MaxRateAge   time.Duration `yaml:"max_rate_age"`
+	MaxMarkupBps int           `yaml:"max_markup_bps"`
+}
+
+type PricingConfig struct {
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+type PairsConfig struct {
+	CacheTTL time.Duration `yaml:"cache_ttl"`
 }
 
 type Config struct {
 	Postgres PostgresConfig `yaml:"postgres"`
 	Exchange ExchangeConfig `yaml:"exchange"`
 	Cron     CronConfig     `yaml:"cron"`
+	Pairs    PairsConfig    `yaml:"pairs"`
+	Server   ServerConfig   `yaml:"server"`
+	Webhooks WebhookConfig  `yaml:"webhooks"`
+	Events   EventsConfig   `yaml:"events"`
+	Convert  ConvertConfig  `yaml:"convert"`
+	Locks    LockConfig     `yaml:"locks"`
+	Pricing  PricingConfig  `yaml:"pricing"`
+}
+
+// DefaultDrainTimeout is how long shutdown waits for running cron jobs when
+// cron.drain_timeout is not set.
+const DefaultDrainTimeout = 30 * time.Second
+
+// DefaultInstanceID identifies this process among replicas when no id is configured.
+func DefaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "plata"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
 }
 
 func LoadConfig() (*Config, error) {
@@ -47,6 +174,26 @@ func LoadConfig() (*Config, error) {
 	if err := decoder.Decode(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to decode config file: %w", err)
 	}
+	if cfg.Cron.InstanceID == "" {
+		cfg.Cron.InstanceID = DefaultInstanceID()
+	}
+	if cfg.Cron.DrainTimeout == 0 {
+		cfg.Cron.DrainTimeout = DefaultDrainTimeout
+	}
 	defer file.Close()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
+
+// validate rejects settings the service must not start with.
+func (c *Config) validate() error {
+	if len(c.Webhooks.AllowedHosts) > 0 && c.Webhooks.Secret == "" {
+		return errors.New("webhooks.secret must be set when webhooks.allowed_hosts is not empty")
+	}
+	if c.Cron.DrainTimeout < 0 {
+		return errors.New("cron.drain_timeout must not be negative")
+	}
+	return nil
+}
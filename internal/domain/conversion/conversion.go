@@ -0,0 +1,64 @@
+package conversion
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RateScale is the number of decimal places of the effective rate of a conversion.
+const RateScale = 10
+
+type Request struct {
+	From     string
+	To       string
+	Amount   decimal.Decimal
+	At       *time.Time
+	Rounding RoundingMode
+}
+
+// Leg is one stored rate a conversion is derived from, inverted when it is quoted
+// the other way round.
+type Leg struct {
+	Pair      string          `json:"pair"`
+	Rate      decimal.Decimal `json:"rate" swaggertype:"string" example:"18.734500"`
+	Inverted  bool            `json:"inverted"`
+	QuoteID   string          `json:"quote_id,omitempty"`
+	FetchedAt time.Time       `json:"fetched_at"`
+}
+
+// Effective returns the rate converting the base of the leg direction into its target.
+func (l *Leg) Effective() decimal.Decimal {
+	if l.Inverted {
+		return decimal.NewFromInt(1).DivRound(l.Rate, RateScale+6)
+	}
+	return l.Rate
+}
+
+type Conversion struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Amount   decimal.Decimal `json:"amount" swaggertype:"string" example:"125.40"`
+	Result   decimal.Decimal `json:"result" swaggertype:"string" example:"2349.30"`
+	Rate     decimal.Decimal `json:"rate" swaggertype:"string" example:"18.7345000000"`
+	Rounding RoundingMode    `json:"rounding"`
+	// QuoteID is the quote the rate comes from, set unless it is triangulated.
+	QuoteID string `json:"quote_id,omitempty"`
+	// RateTime is the fetch time of the oldest leg.
+	RateTime time.Time `json:"rate_time"`
+	Legs     []*Leg    `json:"legs"`
+}
+
+func (c Conversion) MarshalJSON() ([]byte, error) {
+	type Alias Conversion
+	return json.Marshal(&struct {
+		Result string `json:"result"`
+		Rate   string `json:"rate"`
+		*Alias
+	}{
+		Result: c.Result.StringFixed(MinorUnits(c.To)),
+		Rate:   c.Rate.StringFixed(RateScale),
+		Alias:  (*Alias)(&c),
+	})
+}
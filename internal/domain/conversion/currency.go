@@ -0,0 +1,27 @@
+package conversion
+
+import "regexp"
+
+const defaultMinorUnits = 2
+
+var currencyFormat = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// minorUnits lists the ISO 4217 currencies whose minor unit is not a hundredth.
+var minorUnits = map[string]int32{
+	"BHD": 3, "BIF": 0, "CLF": 4, "CLP": 0, "DJF": 0, "GNF": 0, "IQD": 3, "ISK": 0,
+	"JOD": 3, "JPY": 0, "KMF": 0, "KRW": 0, "KWD": 3, "LYD": 3, "OMR": 3, "PYG": 0,
+	"RWF": 0, "TND": 3, "UGX": 0, "UYI": 0, "UYW": 4, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+}
+
+func ValidCurrency(code string) bool {
+	return currencyFormat.MatchString(code)
+}
+
+// MinorUnits returns the number of decimal places amounts in the currency are rounded to.
+func MinorUnits(code string) int32 {
+	if n, ok := minorUnits[code]; ok {
+		return n
+	}
+	return defaultMinorUnits
+}
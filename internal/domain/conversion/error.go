@@ -0,0 +1,11 @@
+package conversion
+
+import "errors"
+
+var (
+	ErrInvalidCurrency = errors.New("invalid currency code, expected ISO 4217 e.g. EUR")
+	ErrInvalidAmount   = errors.New("amount must be a positive decimal")
+	ErrUnknownRounding = errors.New("unknown rounding mode")
+	ErrRateUnavailable = errors.New("no rate available for this conversion")
+	ErrStaleRate       = errors.New("rate is older than the staleness limit")
+)
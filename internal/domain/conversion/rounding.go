@@ -0,0 +1,45 @@
+package conversion
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+type RoundingMode string
+
+const (
+	RoundHalfEven RoundingMode = "half_even"
+	RoundHalfUp   RoundingMode = "half_up"
+	RoundUp       RoundingMode = "up"
+	RoundDown     RoundingMode = "down"
+	RoundCeiling  RoundingMode = "ceiling"
+	RoundFloor    RoundingMode = "floor"
+)
+
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch m := RoundingMode(s); m {
+	case RoundHalfEven, RoundHalfUp, RoundUp, RoundDown, RoundCeiling, RoundFloor:
+		return m, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownRounding, s)
+}
+
+// Round rounds d to places decimal places; half_up rounds ties away from zero,
+// up and down round away from and towards zero.
+func (m RoundingMode) Round(d decimal.Decimal, places int32) decimal.Decimal {
+	switch m {
+	case RoundHalfUp:
+		return d.Round(places)
+	case RoundUp:
+		return d.RoundUp(places)
+	case RoundDown:
+		return d.RoundDown(places)
+	case RoundCeiling:
+		return d.RoundCeil(places)
+	case RoundFloor:
+		return d.RoundFloor(places)
+	default:
+		return d.RoundBank(places)
+	}
+}
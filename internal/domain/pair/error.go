@@ -0,0 +1,11 @@
+package pair
+
+import "errors"
+
+var (
+	ErrPairNotFound     = errors.New("currency pair not found")
+	ErrPairExists       = errors.New("currency pair already exists")
+	ErrInvalidPair      = errors.New("invalid currency pair, expected format BASE/QUOTE")
+	ErrInvalidPrecision = errors.New("invalid precision")
+	ErrUnknownProvider  = errors.New("unknown exchange provider")
+)
@@ -0,0 +1,35 @@
+package pair
+
+import (
+	"regexp"
+	"time"
+)
+
+const (
+	DefaultPrecision = 6
+	MaxPrecision     = 6
+)
+
+var pairFormat = regexp.MustCompile(`^[A-Z]{3}/[A-Z]{3}$`)
+
+type Pair struct {
+	Pair      string    `json:"pair"`
+	Enabled   bool      `json:"enabled"`
+	Precision int       `json:"precision"`
+	Provider  string    `json:"provider,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func Join(base, target string) string {
+	return base + "/" + target
+}
+
+func Validate(p *Pair) error {
+	if !pairFormat.MatchString(p.Pair) {
+		return ErrInvalidPair
+	}
+	if p.Precision < 0 || p.Precision > MaxPrecision {
+		return ErrInvalidPrecision
+	}
+	return nil
+}
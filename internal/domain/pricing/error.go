@@ -0,0 +1,10 @@
+package pricing
+
+import "errors"
+
+var (
+	ErrInvalidRule   = errors.New("invalid pricing rule")
+	ErrRuleExists    = errors.New("a pricing rule with the same tenant, pair and tier already exists")
+	ErrRuleNotFound  = errors.New("pricing rule not found")
+	ErrInvalidAmount = errors.New("amount must be a non-negative decimal")
+)
@@ -0,0 +1,120 @@
+package pricing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"plata/internal/domain/quote"
+)
+
+// MaxMarkupBps bounds the markup of a rule on each side of the mid rate, below 100%
+// so the bid stays positive.
+const MaxMarkupBps = 9999
+
+// Limits of the pricing_rules columns, larger values or more decimals would be
+// rounded or rejected by the database.
+const (
+	maxTenantLength = 64
+	amountScale     = 2
+	pipsScale       = 2
+)
+
+var (
+	maxMinAmount  = decimal.New(1, 18)
+	maxMarkupPips = decimal.New(1, 8)
+)
+
+var pairFormat = regexp.MustCompile(`^[A-Z]{3}/[A-Z]{3}$`)
+
+// Rule widens the mid rate into bid and ask for a tenant and pair, from MinAmount of
+// the base currency upwards. An empty Tenant or Pair applies to all of them.
+type Rule struct {
+	ID         int64           `json:"id"`
+	Tenant     string          `json:"tenant,omitempty"`
+	Pair       string          `json:"pair,omitempty"`
+	MinAmount  decimal.Decimal `json:"min_amount" swaggertype:"string" example:"10000"`
+	MarkupBps  int             `json:"markup_bps"`
+	MarkupPips decimal.Decimal `json:"markup_pips" swaggertype:"string" example:"1.5"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func Validate(r *Rule) error {
+	if r.Pair != "" && !pairFormat.MatchString(r.Pair) {
+		return fmt.Errorf("%w: pair must be formatted BASE/QUOTE", ErrInvalidRule)
+	}
+	if len(r.Tenant) > maxTenantLength {
+		return fmt.Errorf("%w: tenant must be at most %d characters", ErrInvalidRule, maxTenantLength)
+	}
+	if r.MinAmount.IsNegative() || r.MarkupPips.IsNegative() {
+		return fmt.Errorf("%w: min_amount and markup_pips must not be negative", ErrInvalidRule)
+	}
+	if r.MinAmount.GreaterThanOrEqual(maxMinAmount) || !r.MinAmount.Equal(r.MinAmount.Truncate(amountScale)) {
+		return fmt.Errorf("%w: min_amount must be below %s with at most %d decimals", ErrInvalidRule, maxMinAmount, amountScale)
+	}
+	if r.MarkupPips.GreaterThanOrEqual(maxMarkupPips) || !r.MarkupPips.Equal(r.MarkupPips.Truncate(pipsScale)) {
+		return fmt.Errorf("%w: markup_pips must be below %s with at most %d decimals", ErrInvalidRule, maxMarkupPips, pipsScale)
+	}
+	if r.MarkupBps < 0 || r.MarkupBps > MaxMarkupBps {
+		return fmt.Errorf("%w: markup_bps must be between 0 and %d", ErrInvalidRule, MaxMarkupBps)
+	}
+	return nil
+}
+
+func (r *Rule) matches(tenant, pair string, amount decimal.Decimal) bool {
+	return (r.Tenant == "" || r.Tenant == tenant) &&
+		(r.Pair == "" || r.Pair == pair) &&
+		amount.GreaterThanOrEqual(r.MinAmount)
+}
+
+// more reports whether r takes precedence over o: a tenant rule over a global one,
+// then a pair rule over a wildcard, then the higher tier.
+func (r *Rule) more(o *Rule) bool {
+	if (r.Tenant != "") != (o.Tenant != "") {
+		return r.Tenant != ""
+	}
+	if (r.Pair != "") != (o.Pair != "") {
+		return r.Pair != ""
+	}
+	return r.MinAmount.GreaterThan(o.MinAmount)
+}
+
+// Select returns the rule applying to an amount of pair quoted for tenant, nil when
+// none matches.
+func Select(rules []*Rule, tenant, pair string, amount decimal.Decimal) *Rule {
+	var best *Rule
+	for _, r := range rules {
+		if r.matches(tenant, pair, amount) && (best == nil || r.more(best)) {
+			best = r
+		}
+	}
+	return best
+}
+
+// PipSize returns the pip of the quote currency of pair: 0.01 for yen, 0.0001 otherwise.
+func PipSize(pair string) decimal.Decimal {
+	if strings.HasSuffix(pair, "/JPY") {
+		return decimal.New(1, -2)
+	}
+	return decimal.New(1, -4)
+}
+
+// Apply derives the price of a mid rate under rule, bid rounded down and ask up to
+// scale. The bid never drops below one unit of scale, even when the pips exceed the
+// mid rate. Without a rule bid and ask equal the mid rate.
+func Apply(rule *Rule, pair string, mid decimal.Decimal, scale int32) *quote.Price {
+	p := &quote.Price{Mid: mid, Bid: mid, Ask: mid}
+	if rule == nil {
+		return p
+	}
+	half := mid.Mul(decimal.New(int64(rule.MarkupBps), -4)).Add(rule.MarkupPips.Mul(PipSize(pair)))
+	p.Bid = mid.Sub(half).RoundFloor(scale)
+	if !p.Bid.IsPositive() {
+		p.Bid = decimal.New(1, -scale)
+	}
+	p.Ask = mid.Add(half).RoundCeil(scale)
+	p.RuleID = rule.ID
+	return p
+}
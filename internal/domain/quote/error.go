@@ -5,4 +5,14 @@ import "errors"
 var (
 	ErrUnsupportedCurrencyPair = errors.New("unsupported currency pair")
 	ErrQuoteNotFound           = errors.New("quote not found")
+	ErrLeaseLost               = errors.New("quote lease is held by another worker")
+	ErrQuoteExists             = errors.New("a quote with this idempotency key already exists")
+	ErrStreamUnavailable       = errors.New("quote update stream is not available")
+	ErrRateUnavailable         = errors.New("no recent rate to lock for this pair")
+	ErrInvalidLock             = errors.New("invalid lock request")
+	ErrLockNotFound            = errors.New("quote lock not found")
+	ErrLockExpired             = errors.New("quote lock has expired")
+	ErrLockConsumed            = errors.New("quote lock has already been consumed")
+	ErrLockExists              = errors.New("a quote lock with this idempotency key already exists")
+	ErrIdempotencyConflict     = errors.New("idempotency key was already used with different terms")
 )
@@ -0,0 +1,32 @@
+package quote
+
+import "time"
+
+// Domain event types published through the quote_events outbox.
+const (
+	EventRequested = "quote.requested"
+	EventCompleted = "quote.completed"
+	EventFailed    = "quote.failed"
+)
+
+type Event struct {
+	ID         string    `json:"id"`
+	Seq        int64     `json:"seq"`
+	Type       string    `json:"type"`
+	QuoteID    string    `json:"quote_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Quote      *Quote    `json:"quote"`
+}
+
+// CompletionEvent returns the event type for a quote in a terminal status, empty
+// otherwise. Expired quotes are reported as failed.
+func CompletionEvent(q *Quote) string {
+	switch q.Status {
+	case StatusDone:
+		return EventCompleted
+	case StatusFailed, StatusExpired:
+		return EventFailed
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,43 @@
+package quote
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Lock is a firm rate for a pair, taken from a done quote and guaranteed until
+// ExpiresAt; it can be consumed once.
+type Lock struct {
+	Token          string          `json:"token"`
+	QuoteID        string          `json:"quote_id,omitempty"`
+	Currency       string          `json:"currency"`
+	MidRate        decimal.Decimal `json:"mid_rate" swaggertype:"string" example:"1.082300"`
+	Rate           decimal.Decimal `json:"rate" swaggertype:"string" example:"1.083382"`
+	Scale          int32           `json:"-"`
+	MarkupBps      int             `json:"markup_bps"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	ConsumedAt     *time.Time      `json:"consumed_at,omitempty"`
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`
+}
+
+// ApplyMarkup returns mid raised by bps basis points, rounded to scale.
+func ApplyMarkup(mid decimal.Decimal, bps int, scale int32) decimal.Decimal {
+	factor := decimal.NewFromInt(int64(bps)).Shift(-4).Add(decimal.NewFromInt(1))
+	return mid.Mul(factor).Round(scale)
+}
+
+func (l Lock) MarshalJSON() ([]byte, error) {
+	type Alias Lock
+	return json.Marshal(&struct {
+		MidRate string `json:"mid_rate"`
+		Rate    string `json:"rate"`
+		*Alias
+	}{
+		MidRate: l.MidRate.StringFixed(l.Scale),
+		Rate:    l.Rate.StringFixed(l.Scale),
+		Alias:   (*Alias)(&l),
+	})
+}
@@ -0,0 +1,11 @@
+package quote
+
+import "github.com/shopspring/decimal"
+
+// Price is the client-facing rate of a quote, derived from its mid rate by a pricing rule.
+type Price struct {
+	Mid    decimal.Decimal
+	Bid    decimal.Decimal
+	Ask    decimal.Decimal
+	RuleID int64
+}
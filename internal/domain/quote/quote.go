@@ -2,22 +2,49 @@ package quote
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Quote struct {
-	ID             string    `json:"id"`
-	Currency       string    `json:"currency"`
-	Amount         float64   `json:"amount"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	Status         Status    `json:"status"`
-	IdempotencyKey string    `json:"idempotency_key,omitempty"`
+	ID             string          `json:"id"`
+	Currency       string          `json:"currency"`
+	Amount         decimal.Decimal `json:"amount" swaggertype:"string" example:"1.082300"`
+	Scale          int32           `json:"-"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Status         Status          `json:"status"`
+	Provider       string          `json:"provider,omitempty"`
+	Sources        int             `json:"sources,omitempty"`
+	Spread         decimal.Decimal `json:"spread" swaggertype:"string" example:"0.000200"`
+	Derived        bool            `json:"derived"`
+	Legs           []string        `json:"legs,omitempty"`
+	Attempts       int             `json:"attempts"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	ErrorCode      string          `json:"error_code,omitempty"`
+	ErrorMessage   string          `json:"error_message,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	LeaseOwner     string          `json:"-"`
+	LeaseExpiresAt *time.Time      `json:"-"`
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`
+	// CallbackURL may carry a token, it is only read from the stored quote and never encoded.
+	CallbackURL string `json:"-"`
+	Origin      string `json:"origin,omitempty"`
+	// Price is set when the quote is served with a pricing rule applied.
+	Price *Price `json:"-"`
+	// Age is set when the quote is served as the latest rate of its pair.
+	Age *time.Duration `json:"-"`
 }
 
-var AllowedPairs = map[string]struct{}{
-	"EUR/USD": {},
-	"EUR/MXN": {},
-	"EUR/RUB": {},
+// Latest is the newest done quote of a pair, Stale when it is older than requested.
+// RefreshID is the update requested to replace a stale or missing quote.
+type Latest struct {
+	Quote     *Quote
+	Stale     bool
+	RefreshID string
 }
 
 type Status int
@@ -26,14 +53,37 @@ const (
 	StatusUnspecified Status = iota
 	StatusInProgress
 	StatusDone
+	StatusFailed
+	StatusExpired
+)
+
+// Origin tells whether a quote was requested through the API or by the pre-warm job.
+const (
+	OriginRequest = "request"
+	OriginSystem  = "system"
+)
+
+const (
+	ErrorCodeProvider        = "provider_error"
+	ErrorCodeRateUnavailable = "rate_unavailable"
+	ErrorCodeInvalidPair     = "invalid_pair"
+	ErrorCodeExpired         = "max_age_exceeded"
 )
 
+func (s Status) IsTerminal() bool {
+	return s == StatusDone || s == StatusFailed || s == StatusExpired
+}
+
 func ToString(s Status) string {
 	switch s {
 	case StatusInProgress:
 		return "in_progress"
 	case StatusDone:
 		return "done"
+	case StatusFailed:
+		return "failed"
+	case StatusExpired:
+		return "expired"
 	default:
 		return "unspecified"
 	}
@@ -45,6 +95,10 @@ func FromString(s string) Status {
 		return StatusInProgress
 	case "done":
 		return StatusDone
+	case "failed":
+		return StatusFailed
+	case "expired":
+		return StatusExpired
 	default:
 		return StatusUnspecified
 	}
@@ -52,11 +106,62 @@ func FromString(s string) Status {
 
 func (q Quote) MarshalJSON() ([]byte, error) {
 	type Alias Quote
-	return json.Marshal(&struct {
+	aux := &struct {
 		Status string `json:"status"`
+		Amount string `json:"amount"`
+		Spread string `json:"spread"`
+		Mid    string `json:"mid,omitempty"`
+		Bid    string `json:"bid,omitempty"`
+		Ask    string `json:"ask,omitempty"`
+		RuleID int64  `json:"rule_id,omitempty"`
+		Age    *int64 `json:"age_seconds,omitempty"`
 		*Alias
 	}{
 		Status: ToString(q.Status),
+		Amount: q.Amount.StringFixed(q.Scale),
+		Spread: q.Spread.StringFixed(q.Scale),
 		Alias:  (*Alias)(&q),
-	})
+	}
+	if q.Price != nil {
+		aux.Mid = q.Price.Mid.StringFixed(q.Scale)
+		aux.Bid = q.Price.Bid.StringFixed(q.Scale)
+		aux.Ask = q.Price.Ask.StringFixed(q.Scale)
+		aux.RuleID = q.Price.RuleID
+	}
+	if q.Age != nil {
+		age := int64(q.Age.Seconds())
+		aux.Age = &age
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON reverses MarshalJSON, the scale is taken from the amount's decimal places.
+func (q *Quote) UnmarshalJSON(data []byte) error {
+	type Alias Quote
+	aux := &struct {
+		Status string `json:"status"`
+		Amount string `json:"amount"`
+		Spread string `json:"spread"`
+		*Alias
+	}{Alias: (*Alias)(q)}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	q.Status = FromString(aux.Status)
+	if aux.Amount != "" {
+		amount, err := decimal.NewFromString(aux.Amount)
+		if err != nil {
+			return fmt.Errorf("invalid amount: %w", err)
+		}
+		q.Amount = amount
+		q.Scale = -amount.Exponent()
+	}
+	if aux.Spread != "" {
+		spread, err := decimal.NewFromString(aux.Spread)
+		if err != nil {
+			return fmt.Errorf("invalid spread: %w", err)
+		}
+		q.Spread = spread
+	}
+	return nil
 }
@@ -0,0 +1,61 @@
+package quote
+
+import "time"
+
+type RunSummary struct {
+	ID         string                   `json:"id"`
+	Origin     string                   `json:"origin"`
+	StartedAt  time.Time                `json:"started_at"`
+	FinishedAt time.Time                `json:"finished_at"`
+	Updated    int                      `json:"updated"`
+	Failed     int                      `json:"failed"`
+	Skipped    int                      `json:"skipped"`
+	Error      string                   `json:"error,omitempty"`
+	Groups     map[string]*GroupSummary `json:"groups"`
+}
+
+type GroupSummary struct {
+	Updated int    `json:"updated"`
+	Failed  int    `json:"failed"`
+	Skipped int    `json:"skipped"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NewRunSummary starts the summary of a run updating quotes of origin.
+func NewRunSummary(id, origin string, startedAt time.Time) *RunSummary {
+	return &RunSummary{
+		ID:        id,
+		Origin:    origin,
+		StartedAt: startedAt,
+		Groups:    make(map[string]*GroupSummary),
+	}
+}
+
+func (r *RunSummary) Group(base string) *GroupSummary {
+	g, ok := r.Groups[base]
+	if !ok {
+		g = &GroupSummary{}
+		r.Groups[base] = g
+	}
+	return g
+}
+
+// Merge adds the counters of o, keeping its error when set.
+func (g *GroupSummary) Merge(o *GroupSummary) {
+	g.Updated += o.Updated
+	g.Failed += o.Failed
+	g.Skipped += o.Skipped
+	if o.Error != "" {
+		g.Error = o.Error
+	}
+}
+
+func (r *RunSummary) Finish(finishedAt time.Time) {
+	r.FinishedAt = finishedAt
+	r.Updated, r.Failed, r.Skipped = 0, 0, 0
+	for _, g := range r.Groups {
+		r.Updated += g.Updated
+		r.Failed += g.Failed
+		r.Skipped += g.Skipped
+	}
+}
@@ -0,0 +1,71 @@
+package rate
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaxCandles bounds the number of buckets a single candle query may span.
+const MaxCandles = 5000
+
+// Candle summarises the rates of a pair fetched within one bucket.
+type Candle struct {
+	Bucket  time.Time       `json:"time"`
+	Open    decimal.Decimal `json:"open" swaggertype:"string" example:"1.082300"`
+	High    decimal.Decimal `json:"high" swaggertype:"string" example:"1.083100"`
+	Low     decimal.Decimal `json:"low" swaggertype:"string" example:"1.081900"`
+	Close   decimal.Decimal `json:"close" swaggertype:"string" example:"1.082700"`
+	Scale   int32           `json:"-"`
+	Samples int             `json:"samples"`
+}
+
+func (c Candle) MarshalJSON() ([]byte, error) {
+	type Alias Candle
+	return json.Marshal(&struct {
+		Open  string `json:"open"`
+		High  string `json:"high"`
+		Low   string `json:"low"`
+		Close string `json:"close"`
+		*Alias
+	}{
+		Open:  c.Open.StringFixed(c.Scale),
+		High:  c.High.StringFixed(c.Scale),
+		Low:   c.Low.StringFixed(c.Scale),
+		Close: c.Close.StringFixed(c.Scale),
+		Alias: (*Alias)(&c),
+	})
+}
+
+type CandleSeries struct {
+	Pair     string    `json:"pair"`
+	Interval string    `json:"interval"`
+	Candles  []*Candle `json:"candles"`
+}
+
+// CandleQuery selects the candles of the buckets starting in [From, To); From is
+// rounded down to the start of its bucket.
+type CandleQuery struct {
+	Pair     string
+	From     time.Time
+	To       time.Time
+	Interval time.Duration
+}
+
+func (q *CandleQuery) Validate() error {
+	if q.Pair == "" {
+		return ErrInvalidQuery
+	}
+	if !q.From.Before(q.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidQuery)
+	}
+	if q.Interval < MinInterval || q.Interval%time.Second != 0 {
+		return fmt.Errorf("%w: interval must be a whole number of seconds", ErrInvalidQuery)
+	}
+	if q.To.Sub(q.From)/q.Interval > MaxCandles {
+		return fmt.Errorf("%w: more than %d candles requested", ErrInvalidQuery, MaxCandles)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package rate
+
+import "errors"
+
+var (
+	ErrRateNotFound  = errors.New("no rate known at that time")
+	ErrInvalidQuery  = errors.New("invalid rate history query")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
@@ -0,0 +1,108 @@
+package rate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+	// MinInterval is the shortest bucket a history or candle query may use.
+	MinInterval = time.Second
+)
+
+// Rate is one entry of the append-only rate history, recorded whenever a quote is done.
+type Rate struct {
+	ID        int64           `json:"-"`
+	Pair      string          `json:"pair"`
+	Rate      decimal.Decimal `json:"rate" swaggertype:"string" example:"1.082300"`
+	Scale     int32           `json:"-"`
+	Provider  string          `json:"provider,omitempty"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	QuoteID   string          `json:"quote_id,omitempty"`
+	// Bucket is the start of the interval the rate closes, set for downsampled history.
+	Bucket *time.Time `json:"bucket,omitempty"`
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	type Alias Rate
+	return json.Marshal(&struct {
+		Rate string `json:"rate"`
+		*Alias
+	}{
+		Rate:  r.Rate.StringFixed(r.Scale),
+		Alias: (*Alias)(&r),
+	})
+}
+
+// HistoryQuery selects the rates of a pair fetched in [From, To). With an Interval only
+// the last rate of every bucket is returned.
+type HistoryQuery struct {
+	Pair     string
+	From     time.Time
+	To       time.Time
+	Interval time.Duration
+	Limit    int
+	After    *Cursor
+}
+
+func (q *HistoryQuery) Validate() error {
+	if q.Pair == "" {
+		return ErrInvalidQuery
+	}
+	if !q.From.Before(q.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidQuery)
+	}
+	if q.Interval != 0 && q.Interval < MinInterval {
+		return fmt.Errorf("%w: interval must be at least %s", ErrInvalidQuery, MinInterval)
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	q.Limit = min(q.Limit, MaxLimit)
+	return nil
+}
+
+type Page struct {
+	Items      []*Rate `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// Cursor points at the last item of a page: its time (fetch time or bucket start)
+// and history ID, zero for buckets.
+type Cursor struct {
+	At time.Time
+	ID int64
+}
+
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.At.UnixMicro(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	at, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	micros, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{At: time.UnixMicro(micros).UTC(), ID: n}, nil
+}
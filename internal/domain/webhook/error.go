@@ -0,0 +1,9 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrInvalidCallbackURL = errors.New("invalid callback URL")
+	ErrCallbackNotAllowed = errors.New("callback host is not allowed")
+	ErrLeaseLost          = errors.New("delivery lease is held by another worker")
+)
@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"plata/internal/domain/quote"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusDelivered Status = "delivered"
+	StatusFailed    Status = "failed"
+)
+
+// Signature headers sent with every callback. The signature is the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the configured secret.
+const (
+	HeaderSignature = "X-Plata-Signature"
+	HeaderTimestamp = "X-Plata-Timestamp"
+	HeaderDelivery  = "X-Plata-Delivery"
+	HeaderEvent     = "X-Plata-Event"
+)
+
+type Delivery struct {
+	ID             string     `json:"id"`
+	QuoteID        string     `json:"quote_id"`
+	URL            string     `json:"-"`
+	Event          string     `json:"event"`
+	Payload        string     `json:"-"`
+	Status         Status     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LeaseOwner     string     `json:"-"`
+	LeaseExpiresAt *time.Time `json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	Log            []*Attempt `json:"log"`
+}
+
+// Host returns the host of the callback URL, safe to log unlike the full URL which
+// may carry a token.
+func (d *Delivery) Host() string {
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+type Attempt struct {
+	DeliveryID  string    `json:"-"`
+	Attempt     int       `json:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// Payload is the JSON body posted to the callback URL.
+type Payload struct {
+	Event string       `json:"event"`
+	Quote *quote.Quote `json:"quote"`
+}
+
+// EventFor names the callback event of a quote in a terminal status, e.g. quote.done.
+func EventFor(q *quote.Quote) string {
+	return "quote." + quote.ToString(q.Status)
+}
+
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// AllowList holds the hosts callbacks may target, an entry starting with a dot
+// matches every subdomain of it.
+type AllowList []string
+
+func (a AllowList) Check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidCallbackURL
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range a {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return nil
+		}
+	}
+	return ErrCallbackNotAllowed
+}
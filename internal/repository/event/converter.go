@@ -0,0 +1,22 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"plata/internal/domain/quote"
+)
+
+func toDomain(e *entity) (*quote.Event, error) {
+	var q quote.Quote
+	if err := json.Unmarshal([]byte(e.Payload), &q); err != nil {
+		return nil, fmt.Errorf("failed to decode event %s: %w", e.ID, err)
+	}
+	return &quote.Event{
+		ID:         e.ID,
+		Seq:        e.Seq,
+		Type:       e.Type,
+		QuoteID:    e.QuoteID,
+		OccurredAt: e.OccurredAt,
+		Quote:      &q,
+	}, nil
+}
@@ -0,0 +1,12 @@
+package event
+
+import "time"
+
+type entity struct {
+	Seq        int64     `db:"seq"`
+	ID         string    `db:"id"`
+	Type       string    `db:"type"`
+	QuoteID    string    `db:"quote_id"`
+	OccurredAt time.Time `db:"occurred_at"`
+	Payload    string    `db:"payload"`
+}
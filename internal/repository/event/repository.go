@@ -0,0 +1,90 @@
+package event
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"plata/internal/domain/quote"
+	"slices"
+	"time"
+)
+
+type Repository struct {
+	dbP *sqlx.DB
+}
+
+func New(primary *sqlx.DB) *Repository {
+	return &Repository{
+		dbP: primary,
+	}
+}
+
+// PublishPending leases up to limit unpublished events to owner, hands them to publish
+// in order and marks them published once it succeeds. No transaction is held while
+// publishing: concurrent relays skip leased events and a failed publish releases them.
+func (r *Repository) PublishPending(ctx context.Context, owner string, lease time.Duration, limit int, publish func(ctx context.Context, events []*quote.Event) error) (int, error) {
+	const claim = `
+		UPDATE quote_events
+		SET lease_owner = $1, lease_expires_at = $2
+		WHERE seq IN (
+			SELECT seq
+			FROM quote_events
+			WHERE published_at IS NULL
+			  AND (lease_expires_at IS NULL OR lease_expires_at <= $3)
+			ORDER BY seq
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING seq, id, type, quote_id, occurred_at, payload
+	`
+	now := time.Now().UTC()
+	var e []entity
+	if err := r.dbP.SelectContext(ctx, &e, claim, owner, now.Add(lease), now, limit); err != nil {
+		return 0, fmt.Errorf("failed to claim pending events: %w", err)
+	}
+	if len(e) == 0 {
+		return 0, nil
+	}
+	slices.SortFunc(e, func(a, b entity) int { return cmp.Compare(a.Seq, b.Seq) })
+
+	events := make([]*quote.Event, len(e))
+	seqs := make(pq.Int64Array, len(e))
+	for i := range e {
+		seqs[i] = e[i].Seq
+	}
+	var err error
+	for i := range e {
+		if events[i], err = toDomain(&e[i]); err != nil {
+			r.release(ctx, owner, seqs)
+			return 0, err
+		}
+	}
+	if err = publish(ctx, events); err != nil {
+		r.release(ctx, owner, seqs)
+		return 0, fmt.Errorf("failed to publish events: %w", err)
+	}
+
+	const mark = `
+		UPDATE quote_events
+		SET published_at = $1, lease_owner = NULL, lease_expires_at = NULL
+		WHERE seq = ANY($2)
+	`
+	if _, err = r.dbP.ExecContext(ctx, mark, time.Now().UTC(), seqs); err != nil {
+		return 0, fmt.Errorf("failed to mark events published: %w", err)
+	}
+	return len(events), nil
+}
+
+// release hands the leased events back, so the next relay pass retries them without
+// waiting for the lease to expire.
+func (r *Repository) release(ctx context.Context, owner string, seqs pq.Int64Array) {
+	const query = `
+		UPDATE quote_events
+		SET lease_owner = NULL, lease_expires_at = NULL
+		WHERE seq = ANY($1) AND lease_owner = $2
+	`
+	// best effort, the lease expires anyway
+	_, _ = r.dbP.ExecContext(context.WithoutCancel(ctx), query, seqs, owner)
+}
@@ -0,0 +1,125 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"sync"
+)
+
+// Repository holds a session level pg advisory lock on a dedicated primary
+// connection, the lock lives as long as that session.
+type Repository struct {
+	dbP *sqlx.DB
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+func New(primary *sqlx.DB) *Repository {
+	return &Repository{
+		dbP: primary,
+	}
+}
+
+// TryLock takes the lock on a new session named name, false when another session holds it.
+func (r *Repository) TryLock(ctx context.Context, key int64, name string) (bool, error) {
+	conn, err := r.dbP.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to open leader connection: %w", err)
+	}
+	var acquired bool
+	if err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		return false, fmt.Errorf("failed to take advisory lock: %w", err)
+	}
+	if !acquired {
+		_ = conn.Close()
+		return false, nil
+	}
+	if _, err = conn.ExecContext(ctx, `SELECT set_config('application_name', $1, false)`, name); err != nil {
+		_, _ = conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, key)
+		_ = conn.Close()
+		return false, fmt.Errorf("failed to name leader session: %w", err)
+	}
+	r.mu.Lock()
+	r.conn = conn
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Held checks that the session holding the lock is still alive and still owns it.
+func (r *Repository) Held(ctx context.Context, key int64) (bool, error) {
+	r.mu.Lock()
+	conn := r.conn
+	r.mu.Unlock()
+	if conn == nil {
+		return false, nil
+	}
+	const query = `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_locks
+			WHERE locktype = 'advisory'
+			  AND classid = $1 AND objid = $2 AND objsubid = 1
+			  AND pid = pg_backend_pid() AND granted
+		)
+	`
+	var held bool
+	if err := conn.QueryRowContext(ctx, query, uint32(key>>32), uint32(key)).Scan(&held); err != nil {
+		return false, fmt.Errorf("failed to check advisory lock: %w", err)
+	}
+	return held, nil
+}
+
+// Unlock releases the lock and closes its session.
+func (r *Repository) Unlock(ctx context.Context, key int64) error {
+	r.mu.Lock()
+	conn := r.conn
+	r.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	defer r.Close()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+		return fmt.Errorf("failed to release advisory lock: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, `RESET application_name`); err != nil {
+		return fmt.Errorf("failed to reset application name: %w", err)
+	}
+	return nil
+}
+
+// Close drops the session, and with it the lock.
+func (r *Repository) Close() {
+	r.mu.Lock()
+	conn := r.conn
+	r.conn = nil
+	r.mu.Unlock()
+	if conn != nil {
+		_ = conn.Close()
+	}
+}
+
+// Holder returns the name of the session holding the lock, empty when there is none.
+func (r *Repository) Holder(ctx context.Context, key int64) (string, error) {
+	const query = `
+		SELECT a.application_name
+		FROM pg_locks l
+		JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory'
+		  AND l.classid = $1 AND l.objid = $2 AND l.objsubid = 1
+		  AND l.granted
+		LIMIT 1
+	`
+	var name string
+	err := r.dbP.GetContext(ctx, &name, query, uint32(key>>32), uint32(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to look up leader: %w", err)
+	}
+	return name, nil
+}
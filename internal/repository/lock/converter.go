@@ -0,0 +1,48 @@
+package lock
+
+import (
+	"database/sql"
+	"plata/internal/domain/quote"
+)
+
+func toDomain(e *entity) *quote.Lock {
+	l := &quote.Lock{
+		Token:          e.Token,
+		QuoteID:        e.QuoteID.String,
+		Currency:       e.Currency,
+		MidRate:        e.MidRate,
+		Rate:           e.Rate,
+		Scale:          e.Scale,
+		MarkupBps:      e.MarkupBps,
+		CreatedAt:      e.CreatedAt,
+		ExpiresAt:      e.ExpiresAt,
+		IdempotencyKey: e.IdempotencyKey.String,
+	}
+	if e.ConsumedAt.Valid {
+		l.ConsumedAt = &e.ConsumedAt.Time
+	}
+	return l
+}
+
+func toEntity(l *quote.Lock) *entity {
+	e := &entity{
+		Token:     l.Token,
+		Currency:  l.Currency,
+		MidRate:   l.MidRate,
+		Rate:      l.Rate,
+		Scale:     l.Scale,
+		MarkupBps: l.MarkupBps,
+		CreatedAt: l.CreatedAt.UTC(),
+		ExpiresAt: l.ExpiresAt.UTC(),
+	}
+	if l.QuoteID != "" {
+		e.QuoteID = sql.NullString{String: l.QuoteID, Valid: true}
+	}
+	if l.ConsumedAt != nil {
+		e.ConsumedAt = sql.NullTime{Time: l.ConsumedAt.UTC(), Valid: true}
+	}
+	if l.IdempotencyKey != "" {
+		e.IdempotencyKey = sql.NullString{String: l.IdempotencyKey, Valid: true}
+	}
+	return e
+}
@@ -0,0 +1,22 @@
+package lock
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type entity struct {
+	Token          string          `db:"token"`
+	QuoteID        sql.NullString  `db:"quote_id"`
+	Currency       string          `db:"currency"`
+	MidRate        decimal.Decimal `db:"mid_rate"`
+	Rate           decimal.Decimal `db:"rate"`
+	Scale          int32           `db:"scale"`
+	MarkupBps      int             `db:"markup_bps"`
+	CreatedAt      time.Time       `db:"created_at"`
+	ExpiresAt      time.Time       `db:"expires_at"`
+	ConsumedAt     sql.NullTime    `db:"consumed_at"`
+	IdempotencyKey sql.NullString  `db:"idempotency_key"`
+}
@@ -0,0 +1,92 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"plata/internal/domain/quote"
+	"time"
+)
+
+const uniqueViolation = "23505"
+
+const lockColumns = "token, quote_id, currency, mid_rate, rate, scale, markup_bps, created_at, expires_at, consumed_at, idempotency_key"
+
+type Repository struct {
+	dbP *sqlx.DB
+}
+
+// New reads and writes locks on the primary only: a lock is consumed right after it
+// is created and replica lag would reject it.
+func New(primary *sqlx.DB) *Repository {
+	return &Repository{
+		dbP: primary,
+	}
+}
+
+func (r *Repository) Create(ctx context.Context, l *quote.Lock) error {
+	const query = `
+		INSERT INTO quote_locks (` + lockColumns + `)
+		VALUES (:token, :quote_id, :currency, :mid_rate, :rate, :scale, :markup_bps, :created_at, :expires_at, :consumed_at, :idempotency_key)
+	`
+	if _, err := r.dbP.NamedExecContext(ctx, query, toEntity(l)); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "quote_locks_idempotency_key_key" {
+			return quote.ErrLockExists
+		}
+		return fmt.Errorf("failed to save quote lock: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetByIdempotencyKey(ctx context.Context, key string) (*quote.Lock, error) {
+	const query = `
+		SELECT ` + lockColumns + `
+		FROM quote_locks
+		WHERE idempotency_key = $1
+	`
+	var e entity
+	if err := r.dbP.GetContext(ctx, &e, query, key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get quote lock by idempotency key: %w", err)
+	}
+	return toDomain(&e), nil
+}
+
+// Consume marks the lock used at now, exactly once and only before it expires.
+func (r *Repository) Consume(ctx context.Context, token string, now time.Time) (*quote.Lock, error) {
+	const query = `
+		UPDATE quote_locks
+		SET consumed_at = $2
+		WHERE token = $1 AND consumed_at IS NULL AND expires_at > $2
+		RETURNING ` + lockColumns
+	var e entity
+	err := r.dbP.GetContext(ctx, &e, query, token, now.UTC())
+	if err == nil {
+		return toDomain(&e), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to consume quote lock: %w", err)
+	}
+
+	const lookup = `
+		SELECT ` + lockColumns + `
+		FROM quote_locks
+		WHERE token = $1
+	`
+	if err = r.dbP.GetContext(ctx, &e, lookup, token); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, quote.ErrLockNotFound
+		}
+		return nil, fmt.Errorf("failed to get quote lock: %w", err)
+	}
+	if e.ConsumedAt.Valid {
+		return nil, quote.ErrLockConsumed
+	}
+	return nil, quote.ErrLockExpired
+}
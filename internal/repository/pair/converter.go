@@ -0,0 +1,30 @@
+package pair
+
+import (
+	"database/sql"
+	"plata/internal/domain/pair"
+)
+
+func toDomain(e *entity) *pair.Pair {
+	return &pair.Pair{
+		Pair:      e.Pair,
+		Enabled:   e.Enabled,
+		Precision: e.Precision,
+		Provider:  e.Provider.String,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+func toEntity(p *pair.Pair) *entity {
+	var provider sql.NullString
+	if p.Provider != "" {
+		provider = sql.NullString{String: p.Provider, Valid: true}
+	}
+	return &entity{
+		Pair:      p.Pair,
+		Enabled:   p.Enabled,
+		Precision: p.Precision,
+		Provider:  provider,
+		CreatedAt: p.CreatedAt.UTC(),
+	}
+}
@@ -0,0 +1,14 @@
+package pair
+
+import (
+	"database/sql"
+	"time"
+)
+
+type entity struct {
+	Pair      string         `db:"pair"`
+	Enabled   bool           `db:"enabled"`
+	Precision int            `db:"precision"`
+	Provider  sql.NullString `db:"provider"`
+	CreatedAt time.Time      `db:"created_at"`
+}
@@ -0,0 +1,76 @@
+package pair
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"plata/internal/domain/pair"
+)
+
+const uniqueViolation = "23505"
+
+type Repository struct {
+	dbP *sqlx.DB
+	dbR *sqlx.DB
+}
+
+func New(primary, replica *sqlx.DB) *Repository {
+	return &Repository{
+		dbP: primary,
+		dbR: replica,
+	}
+}
+
+func (r *Repository) List(ctx context.Context) ([]*pair.Pair, error) {
+	const query = `
+		SELECT pair, enabled, precision, provider, created_at
+		FROM currency_pairs
+		ORDER BY pair
+	`
+	var e []entity
+	if err := r.dbR.SelectContext(ctx, &e, query); err != nil {
+		return nil, fmt.Errorf("failed to list currency pairs: %w", err)
+	}
+	pairs := make([]*pair.Pair, len(e))
+	for i := range e {
+		pairs[i] = toDomain(&e[i])
+	}
+	return pairs, nil
+}
+
+func (r *Repository) Create(ctx context.Context, p *pair.Pair) error {
+	const query = `
+		INSERT INTO currency_pairs (pair, enabled, precision, provider, created_at)
+		VALUES (:pair, :enabled, :precision, :provider, :created_at)
+	`
+	_, err := r.dbP.NamedExecContext(ctx, query, toEntity(p))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return pair.ErrPairExists
+		}
+		return fmt.Errorf("failed to create currency pair: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) SetEnabled(ctx context.Context, name string, enabled bool) (*pair.Pair, error) {
+	const query = `
+		UPDATE currency_pairs
+		SET enabled = $1
+		WHERE pair = $2
+		RETURNING pair, enabled, precision, provider, created_at
+	`
+	var e entity
+	err := r.dbP.GetContext(ctx, &e, query, enabled, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pair.ErrPairNotFound
+		}
+		return nil, fmt.Errorf("failed to update currency pair: %w", err)
+	}
+	return toDomain(&e), nil
+}
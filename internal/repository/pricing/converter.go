@@ -0,0 +1,37 @@
+package pricing
+
+import (
+	"database/sql"
+	"plata/internal/domain/pricing"
+)
+
+func toDomain(e *entity) *pricing.Rule {
+	return &pricing.Rule{
+		ID:         e.ID,
+		Tenant:     e.Tenant.String,
+		Pair:       e.Pair.String,
+		MinAmount:  e.MinAmount,
+		MarkupBps:  e.MarkupBps,
+		MarkupPips: e.MarkupPips,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+func toEntity(r *pricing.Rule) *entity {
+	return &entity{
+		ID:         r.ID,
+		Tenant:     nullString(r.Tenant),
+		Pair:       nullString(r.Pair),
+		MinAmount:  r.MinAmount,
+		MarkupBps:  r.MarkupBps,
+		MarkupPips: r.MarkupPips,
+		CreatedAt:  r.CreatedAt.UTC(),
+	}
+}
+
+func nullString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
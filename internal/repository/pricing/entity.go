@@ -0,0 +1,18 @@
+package pricing
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type entity struct {
+	ID         int64           `db:"id"`
+	Tenant     sql.NullString  `db:"tenant"`
+	Pair       sql.NullString  `db:"pair"`
+	MinAmount  decimal.Decimal `db:"min_amount"`
+	MarkupBps  int             `db:"markup_bps"`
+	MarkupPips decimal.Decimal `db:"markup_pips"`
+	CreatedAt  time.Time       `db:"created_at"`
+}
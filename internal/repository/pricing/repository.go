@@ -0,0 +1,75 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"plata/internal/domain/pricing"
+)
+
+const uniqueViolation = "23505"
+
+type Repository struct {
+	dbP *sqlx.DB
+	dbR *sqlx.DB
+}
+
+func New(primary, replica *sqlx.DB) *Repository {
+	return &Repository{
+		dbP: primary,
+		dbR: replica,
+	}
+}
+
+func (r *Repository) List(ctx context.Context) ([]*pricing.Rule, error) {
+	const query = `
+		SELECT id, tenant, pair, min_amount, markup_bps, markup_pips, created_at
+		FROM pricing_rules
+		ORDER BY id
+	`
+	var e []entity
+	if err := r.dbR.SelectContext(ctx, &e, query); err != nil {
+		return nil, fmt.Errorf("failed to list pricing rules: %w", err)
+	}
+	rules := make([]*pricing.Rule, len(e))
+	for i := range e {
+		rules[i] = toDomain(&e[i])
+	}
+	return rules, nil
+}
+
+func (r *Repository) Create(ctx context.Context, rule *pricing.Rule) error {
+	const query = `
+		INSERT INTO pricing_rules (tenant, pair, min_amount, markup_bps, markup_pips, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	e := toEntity(rule)
+	err := r.dbP.GetContext(ctx, &rule.ID, query, e.Tenant, e.Pair, e.MinAmount, e.MarkupBps, e.MarkupPips, e.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return pricing.ErrRuleExists
+		}
+		return fmt.Errorf("failed to create pricing rule: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	const query = `DELETE FROM pricing_rules WHERE id = $1`
+	res, err := r.dbP.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete pricing rule: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete pricing rule: %w", err)
+	}
+	if n == 0 {
+		return pricing.ErrRuleNotFound
+	}
+	return nil
+}
@@ -2,7 +2,9 @@ package quote
 
 import (
 	"database/sql"
+	"encoding/json"
 	"plata/internal/domain/quote"
+	"time"
 )
 
 func toDomain(qr *entity) *quote.Quote {
@@ -15,23 +17,102 @@ func toDomain(qr *entity) *quote.Quote {
 		ID:             qr.ID,
 		Currency:       qr.Currency,
 		Amount:         qr.Amount,
+		Scale:          qr.Scale,
 		UpdatedAt:      qr.UpdatedAt,
 		Status:         quote.FromString(qr.Status),
+		Provider:       qr.Provider.String,
+		Sources:        qr.Sources,
+		Spread:         qr.Spread,
+		Derived:        qr.Derived,
+		Legs:           qr.Legs,
+		Attempts:       qr.Attempts,
+		LastAttemptAt:  timePtr(qr.LastAttemptAt),
+		NextAttemptAt:  timePtr(qr.NextAttemptAt),
+		LastError:      qr.LastError.String,
+		ErrorCode:      qr.ErrorCode.String,
+		ErrorMessage:   qr.ErrorMessage.String,
+		CreatedAt:      qr.CreatedAt,
+		LeaseOwner:     qr.LeaseOwner.String,
+		LeaseExpiresAt: timePtr(qr.LeaseExpiresAt),
 		IdempotencyKey: key,
+		CallbackURL:    qr.CallbackURL.String,
+		Origin:         qr.Origin,
 	}
 }
 
+// toEntity converts timestamps to UTC, the TIMESTAMP columns keep no time zone and
+// are read back as UTC.
 func toEntity(q *quote.Quote) *entity {
 	var key sql.NullString
 	if q.IdempotencyKey != "" {
 		key = sql.NullString{String: q.IdempotencyKey, Valid: true}
 	}
+	origin := q.Origin
+	if origin == "" {
+		origin = quote.OriginRequest
+	}
 	return &entity{
 		ID:             q.ID,
 		Currency:       q.Currency,
 		Amount:         q.Amount,
-		UpdatedAt:      q.UpdatedAt,
+		Scale:          q.Scale,
+		UpdatedAt:      q.UpdatedAt.UTC(),
 		Status:         quote.ToString(q.Status),
+		Provider:       nullString(q.Provider),
+		Sources:        q.Sources,
+		Spread:         q.Spread,
+		Derived:        q.Derived,
+		Legs:           q.Legs,
+		Attempts:       q.Attempts,
+		LastAttemptAt:  nullTime(q.LastAttemptAt),
+		NextAttemptAt:  nullTime(q.NextAttemptAt),
+		LastError:      nullString(q.LastError),
+		ErrorCode:      nullString(q.ErrorCode),
+		ErrorMessage:   nullString(q.ErrorMessage),
+		CreatedAt:      q.CreatedAt.UTC(),
+		LeaseOwner:     nullString(q.LeaseOwner),
+		LeaseExpiresAt: nullTime(q.LeaseExpiresAt),
 		IdempotencyKey: key,
+		CallbackURL:    nullString(q.CallbackURL),
+		Origin:         origin,
+	}
+}
+
+func nullString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func toRunEntity(r *quote.RunSummary) (*runEntity, error) {
+	groups, err := json.Marshal(r.Groups)
+	if err != nil {
+		return nil, err
 	}
+	return &runEntity{
+		ID:         r.ID,
+		Origin:     r.Origin,
+		StartedAt:  r.StartedAt.UTC(),
+		FinishedAt: r.FinishedAt.UTC(),
+		Updated:    r.Updated,
+		Failed:     r.Failed,
+		Skipped:    r.Skipped,
+		Error:      nullString(r.Error),
+		Groups:     string(groups),
+	}, nil
 }
@@ -3,13 +3,45 @@ package quote
 import (
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 )
 
 type entity struct {
-	ID             string         `db:"id"`
-	Currency       string         `db:"currency"`
-	Amount         float64        `db:"amount"`
-	UpdatedAt      time.Time      `db:"updated_at"`
-	Status         string         `db:"status"`
-	IdempotencyKey sql.NullString `db:"idempotency_key"`
+	ID             string          `db:"id"`
+	Currency       string          `db:"currency"`
+	Amount         decimal.Decimal `db:"amount"`
+	Scale          int32           `db:"scale"`
+	UpdatedAt      time.Time       `db:"updated_at"`
+	Status         string          `db:"status"`
+	Provider       sql.NullString  `db:"provider"`
+	Sources        int             `db:"sources"`
+	Spread         decimal.Decimal `db:"spread"`
+	Derived        bool            `db:"derived"`
+	Legs           pq.StringArray  `db:"legs"`
+	Attempts       int             `db:"attempts"`
+	LastAttemptAt  sql.NullTime    `db:"last_attempt_at"`
+	NextAttemptAt  sql.NullTime    `db:"next_attempt_at"`
+	LastError      sql.NullString  `db:"last_error"`
+	ErrorCode      sql.NullString  `db:"error_code"`
+	ErrorMessage   sql.NullString  `db:"error_message"`
+	CreatedAt      time.Time       `db:"created_at"`
+	LeaseOwner     sql.NullString  `db:"lease_owner"`
+	LeaseExpiresAt sql.NullTime    `db:"lease_expires_at"`
+	IdempotencyKey sql.NullString  `db:"idempotency_key"`
+	CallbackURL    sql.NullString  `db:"callback_url"`
+	Origin         string          `db:"origin"`
+}
+
+type runEntity struct {
+	ID         string         `db:"id"`
+	Origin     string         `db:"origin"`
+	StartedAt  time.Time      `db:"started_at"`
+	FinishedAt time.Time      `db:"finished_at"`
+	Updated    int            `db:"updated"`
+	Failed     int            `db:"failed"`
+	Skipped    int            `db:"skipped"`
+	Error      sql.NullString `db:"error"`
+	Groups     string         `db:"groups"`
 }
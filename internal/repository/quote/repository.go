@@ -3,27 +3,48 @@ package quote
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"plata/internal/domain/quote"
+	"plata/internal/domain/webhook"
+	"time"
 )
 
+const uniqueViolation = "23505"
+
+// ChannelQuoteRequested is notified with the quote currency pair whenever a new
+// update request is stored.
+const ChannelQuoteRequested = "quote_requested"
+
+// ChannelQuoteUpdated is notified with the JSON encoded quote whenever an update
+// reaches a terminal status.
+const ChannelQuoteUpdated = "quote_updated"
+
+const quoteColumns = "id, currency, amount, scale, status, provider, sources, spread, derived, legs, attempts, last_attempt_at, next_attempt_at, last_error, error_code, error_message, created_at, lease_owner, lease_expires_at, updated_at, idempotency_key, callback_url, origin"
+
 type Repository struct {
-	dbP *sqlx.DB
-	dbR *sqlx.DB
+	dbP    *sqlx.DB
+	dbR    *sqlx.DB
+	events bool
 }
 
-func New(primary, replica *sqlx.DB) *Repository {
+// New returns a quote repository, quote domain events are only written to the
+// quote_events outbox when events is set.
+func New(primary, replica *sqlx.DB, events bool) *Repository {
 	return &Repository{
-		dbP: primary,
-		dbR: replica,
+		dbP:    primary,
+		dbR:    replica,
+		events: events,
 	}
 }
 
 func (r *Repository) GetByID(ctx context.Context, id string) (*quote.Quote, error) {
 	const query = `
-		SELECT id, currency, amount, status, updated_at, idempotency_key
+		SELECT ` + quoteColumns + `
 		FROM quotes
 		WHERE id = $1
 	`
@@ -40,14 +61,14 @@ func (r *Repository) GetByID(ctx context.Context, id string) (*quote.Quote, erro
 
 func (r *Repository) GetLatestByCurrency(ctx context.Context, currency string) (*quote.Quote, error) {
 	query := `
-		SELECT id, currency, amount, status, updated_at, idempotency_key
+		SELECT ` + quoteColumns + `
 		FROM quotes
-		WHERE currency = $1
+		WHERE currency = $1 AND status = $2
 		ORDER BY updated_at DESC
 		LIMIT 1
 	`
 	var e entity
-	err := r.dbR.GetContext(ctx, &e, query, currency)
+	err := r.dbR.GetContext(ctx, &e, query, currency, quote.ToString(quote.StatusDone))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, quote.ErrQuoteNotFound
@@ -58,35 +79,223 @@ func (r *Repository) GetLatestByCurrency(ctx context.Context, currency string) (
 	return toDomain(&e), nil
 }
 
+// GetPendingByCurrency returns the newest in-progress update of a pair, nil when there is none.
+func (r *Repository) GetPendingByCurrency(ctx context.Context, currency string) (*quote.Quote, error) {
+	const query = `
+		SELECT ` + quoteColumns + `
+		FROM quotes
+		WHERE currency = $1 AND status = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	var e entity
+	// read the primary: a refresh requested a moment ago may not be on the replica yet
+	err := r.dbP.GetContext(ctx, &e, query, currency, quote.ToString(quote.StatusInProgress))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pending quote: %w", err)
+	}
+	return toDomain(&e), nil
+}
+
+// Update stores the in-progress quote and releases its lease, it fails with
+// ErrLeaseLost without any side effect when another worker has claimed or completed
+// the quote in the meantime. A quote without lease is only written while unleased.
 func (r *Repository) Update(ctx context.Context, q *quote.Quote) error {
-	_, err := r.dbP.ExecContext(ctx,
-		`UPDATE quotes 
-		 SET amount = $1, status = $2, updated_at = $3 
-		 WHERE id = $4`,
-		q.Amount, quote.ToString(q.Status), q.UpdatedAt, q.ID,
-	)
+	query := `
+		UPDATE quotes
+		SET amount = :amount, status = :status, provider = :provider,
+		    sources = :sources, spread = :spread, derived = :derived, legs = :legs,
+		    attempts = :attempts, last_attempt_at = :last_attempt_at, next_attempt_at = :next_attempt_at,
+		    last_error = :last_error, error_code = :error_code, error_message = :error_message,
+		    lease_owner = NULL, lease_expires_at = NULL, updated_at = :updated_at
+		WHERE id = :id
+		  AND status = 'in_progress'
+		  AND lease_owner IS NOT DISTINCT FROM :lease_owner
+	`
+	tx, err := r.dbP.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.NamedExecContext(ctx, query, toEntity(q))
+	if err != nil {
+		return fmt.Errorf("failed to update quote: %w", err)
+	}
+	affected, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to update quote: %w", err)
 	}
+	if affected == 0 {
+		return quote.ErrLeaseLost
+	}
+	if q.Status.IsTerminal() {
+		payload, err := json.Marshal(q)
+		if err != nil {
+			return fmt.Errorf("failed to encode quote notification: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, ChannelQuoteUpdated, string(payload)); err != nil {
+			return fmt.Errorf("failed to notify quote update: %w", err)
+		}
+		if err = r.appendEvent(ctx, tx, quote.CompletionEvent(q), q, payload, q.UpdatedAt); err != nil {
+			return err
+		}
+		if q.Status == quote.StatusDone {
+			if err = appendRate(ctx, tx, q); err != nil {
+				return err
+			}
+		}
+		if q.CallbackURL != "" {
+			if err = enqueueCallback(ctx, tx, q); err != nil {
+				return err
+			}
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit quote update: %w", err)
+	}
+	q.LeaseOwner = ""
+	q.LeaseExpiresAt = nil
+	return nil
+}
+
+// appendEvent records a domain event in the quote_events outbox, the relay publishes
+// it once the surrounding transaction has committed. Nothing is written when events
+// are disabled.
+func (r *Repository) appendEvent(ctx context.Context, tx *sqlx.Tx, eventType string, q *quote.Quote, payload []byte, at time.Time) error {
+	if !r.events {
+		return nil
+	}
+	const query = `
+		INSERT INTO quote_events (id, type, quote_id, occurred_at, payload)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := tx.ExecContext(ctx, query, uuid.NewString(), eventType, q.ID, at.UTC(), string(payload)); err != nil {
+		return fmt.Errorf("failed to append quote event: %w", err)
+	}
+	return nil
+}
+
+// appendRate adds the rate of a done quote to the append-only quote_rates history.
+func appendRate(ctx context.Context, tx *sqlx.Tx, q *quote.Quote) error {
+	const query = `
+		INSERT INTO quote_rates (pair, rate, scale, provider, fetched_at, quote_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err := tx.ExecContext(ctx, query, q.Currency, q.Amount, q.Scale, nullString(q.Provider), q.UpdatedAt.UTC(), q.ID); err != nil {
+		return fmt.Errorf("failed to record rate history: %w", err)
+	}
+	return nil
+}
+
+// enqueueCallback stores the callback of a finished quote in the webhook outbox, as part
+// of the transaction completing the quote so it survives a restart.
+func enqueueCallback(ctx context.Context, tx *sqlx.Tx, q *quote.Quote) error {
+	event := webhook.EventFor(q)
+	payload, err := json.Marshal(webhook.Payload{Event: event, Quote: q})
+	if err != nil {
+		return fmt.Errorf("failed to encode callback payload: %w", err)
+	}
+	const query = `
+		INSERT INTO webhook_deliveries (id, quote_id, url, event, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		uuid.NewString(), q.ID, q.CallbackURL, event, string(payload), string(webhook.StatusPending), q.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue callback: %w", err)
+	}
 	return nil
 }
 
 func (r *Repository) Save(ctx context.Context, q *quote.Quote) error {
 	query := `
-		INSERT INTO quotes (id, currency, amount, status, updated_at, idempotency_key)
-		VALUES (:id, :currency, :amount, :status, :updated_at, :idempotency_key)
+		INSERT INTO quotes (id, currency, amount, scale, status, provider, created_at, updated_at, idempotency_key, callback_url, origin)
+		VALUES (:id, :currency, :amount, :scale, :status, :provider, :created_at, :updated_at, :idempotency_key, :callback_url, :origin)
 	`
-	rec := toEntity(q)
-	_, err := r.dbP.NamedExecContext(ctx, query, rec)
+	tx, err := r.dbP.BeginTxx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.NamedExecContext(ctx, query, toEntity(q)); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "quotes_idempotency_key_key" {
+			return quote.ErrQuoteExists
+		}
 		return fmt.Errorf("failed to save quote: %w", err)
 	}
+	payload, err := json.Marshal(q)
+	if err != nil {
+		return fmt.Errorf("failed to encode quote event: %w", err)
+	}
+	if err = r.appendEvent(ctx, tx, quote.EventRequested, q, payload, q.CreatedAt); err != nil {
+		return err
+	}
+	// Delivered to listeners only once the transaction commits.
+	if _, err = tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, ChannelQuoteRequested, q.Currency); err != nil {
+		return fmt.Errorf("failed to notify quote request: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit quote: %w", err)
+	}
+	return nil
+}
+
+// SaveLeased stores new quotes already leased to their LeaseOwner in one transaction,
+// without notifying the updaters of other instances.
+func (r *Repository) SaveLeased(ctx context.Context, quotes []*quote.Quote) error {
+	query := `
+		INSERT INTO quotes (id, currency, amount, scale, status, created_at, updated_at, lease_owner, lease_expires_at, origin)
+		VALUES (:id, :currency, :amount, :scale, :status, :created_at, :updated_at, :lease_owner, :lease_expires_at, :origin)
+	`
+	tx, err := r.dbP.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, q := range quotes {
+		if _, err = tx.NamedExecContext(ctx, query, toEntity(q)); err != nil {
+			return fmt.Errorf("failed to save quote: %w", err)
+		}
+		payload, err := json.Marshal(q)
+		if err != nil {
+			return fmt.Errorf("failed to encode quote event: %w", err)
+		}
+		if err = r.appendEvent(ctx, tx, quote.EventRequested, q, payload, q.CreatedAt); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit quotes: %w", err)
+	}
 	return nil
 }
 
+// ListPendingSystemCurrencies returns the pairs having an in-progress system-originated quote.
+func (r *Repository) ListPendingSystemCurrencies(ctx context.Context) ([]string, error) {
+	const query = `
+		SELECT DISTINCT currency
+		FROM quotes
+		WHERE status = $1 AND origin = $2
+	`
+	var currencies []string
+	err := r.dbP.SelectContext(ctx, &currencies, query, quote.ToString(quote.StatusInProgress), quote.OriginSystem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending system quotes: %w", err)
+	}
+	return currencies, nil
+}
+
 func (r *Repository) GetByIdempotencyKey(ctx context.Context, key string) (*quote.Quote, error) {
 	query := `
-		SELECT id, currency, amount, status, updated_at, idempotency_key
+		SELECT ` + quoteColumns + `
 		FROM quotes
 		WHERE idempotency_key = $1
 	`
@@ -102,14 +311,15 @@ func (r *Repository) GetByIdempotencyKey(ctx context.Context, key string) (*quot
 	return toDomain(&e), nil
 }
 
-func (r *Repository) GetInProgressQuotes(ctx context.Context) ([]*quote.Quote, error) {
+func (r *Repository) GetInProgressQuotes(ctx context.Context, now time.Time) ([]*quote.Quote, error) {
 	const query = `
-		SELECT id, currency, amount, status, updated_at, idempotency_key
+		SELECT ` + quoteColumns + `
 		FROM quotes
 		WHERE status = $1
+		  AND (next_attempt_at IS NULL OR next_attempt_at <= $2)
 	`
 	var e []entity
-	err := r.dbR.SelectContext(ctx, &e, query, quote.ToString(quote.StatusInProgress))
+	err := r.dbR.SelectContext(ctx, &e, query, quote.ToString(quote.StatusInProgress), now.UTC())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get quotes: %w", err)
 	}
@@ -120,3 +330,110 @@ func (r *Repository) GetInProgressQuotes(ctx context.Context) ([]*quote.Quote, e
 	}
 	return quotes, nil
 }
+
+func (r *Repository) SaveRun(ctx context.Context, run *quote.RunSummary) error {
+	query := `
+		INSERT INTO quote_update_runs (id, origin, started_at, finished_at, updated, failed, skipped, error, groups)
+		VALUES (:id, :origin, :started_at, :finished_at, :updated, :failed, :skipped, :error, :groups)
+	`
+	rec, err := toRunEntity(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode update run: %w", err)
+	}
+	if _, err = r.dbP.NamedExecContext(ctx, query, rec); err != nil {
+		return fmt.Errorf("failed to save update run: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueQuotes leases up to limit due in-progress quotes to owner, rows locked or
+// leased by other replicas are skipped. A non-empty base restricts the claim to
+// pairs quoted in that currency.
+func (r *Repository) ClaimDueQuotes(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int, base string) ([]*quote.Quote, error) {
+	const query = `
+		UPDATE quotes
+		SET lease_owner = $1, lease_expires_at = $2
+		WHERE id IN (
+			SELECT id
+			FROM quotes
+			WHERE status = $3
+			  AND (next_attempt_at IS NULL OR next_attempt_at <= $4)
+			  AND (lease_expires_at IS NULL OR lease_expires_at <= $4)
+			  AND ($6 = '' OR split_part(currency, '/', 1) = $6)
+			ORDER BY created_at
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + quoteColumns
+	var e []entity
+	err := r.dbP.SelectContext(ctx, &e, query,
+		owner, now.Add(lease).UTC(), quote.ToString(quote.StatusInProgress), now.UTC(), limit, base,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due quotes: %w", err)
+	}
+
+	quotes := make([]*quote.Quote, len(e))
+	for i := range e {
+		quotes[i] = toDomain(&e[i])
+	}
+	return quotes, nil
+}
+
+// ListDoneAfter returns done quotes of the given pairs written after the quote afterID,
+// oldest first. Nothing is returned when afterID is unknown.
+func (r *Repository) ListDoneAfter(ctx context.Context, currencies []string, afterID string, limit int) ([]*quote.Quote, error) {
+	const query = `
+		SELECT ` + quoteColumns + `
+		FROM quotes
+		WHERE status = $1
+		  AND currency = ANY($2)
+		  AND (updated_at, id) > (SELECT updated_at, id FROM quotes WHERE id = $3)
+		ORDER BY updated_at, id
+		LIMIT $4
+	`
+	var e []entity
+	err := r.dbR.SelectContext(ctx, &e, query,
+		quote.ToString(quote.StatusDone), pq.StringArray(currencies), afterID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list done quotes: %w", err)
+	}
+
+	quotes := make([]*quote.Quote, len(e))
+	for i := range e {
+		quotes[i] = toDomain(&e[i])
+	}
+	return quotes, nil
+}
+
+func (r *Repository) PurgeRuns(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.dbP.ExecContext(ctx, `DELETE FROM quote_update_runs WHERE started_at < $1`, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge update runs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// PurgeOutbox deletes the quote events published and the callbacks delivered or
+// failed before the given time, and returns how many rows were deleted.
+func (r *Repository) PurgeOutbox(ctx context.Context, before time.Time) (int64, error) {
+	const query = `
+		WITH events AS (
+			DELETE FROM quote_events
+			WHERE published_at < $1
+			RETURNING 1
+		), deliveries AS (
+			DELETE FROM webhook_deliveries
+			WHERE status IN ($2, $3) AND created_at < $1
+			RETURNING 1
+		)
+		SELECT (SELECT count(*) FROM events) + (SELECT count(*) FROM deliveries)
+	`
+	var deleted int64
+	err := r.dbP.GetContext(ctx, &deleted, query, before.UTC(), string(webhook.StatusDelivered), string(webhook.StatusFailed))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,31 @@
+package rate
+
+import "plata/internal/domain/rate"
+
+func toDomain(e *entity) *rate.Rate {
+	r := &rate.Rate{
+		ID:        e.ID,
+		Pair:      e.Pair,
+		Rate:      e.Rate,
+		Scale:     e.Scale,
+		Provider:  e.Provider.String,
+		FetchedAt: e.FetchedAt,
+		QuoteID:   e.QuoteID.String,
+	}
+	if e.Bucket.Valid {
+		r.Bucket = &e.Bucket.Time
+	}
+	return r
+}
+
+func candleToDomain(e *candleEntity) *rate.Candle {
+	return &rate.Candle{
+		Bucket:  e.Bucket,
+		Open:    e.Open,
+		High:    e.High,
+		Low:     e.Low,
+		Close:   e.Close,
+		Scale:   e.Scale,
+		Samples: e.Samples,
+	}
+}
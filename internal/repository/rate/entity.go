@@ -0,0 +1,29 @@
+package rate
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type entity struct {
+	ID        int64           `db:"id"`
+	Pair      string          `db:"pair"`
+	Rate      decimal.Decimal `db:"rate"`
+	Scale     int32           `db:"scale"`
+	Provider  sql.NullString  `db:"provider"`
+	FetchedAt time.Time       `db:"fetched_at"`
+	QuoteID   sql.NullString  `db:"quote_id"`
+	Bucket    sql.NullTime    `db:"bucket"`
+}
+
+type candleEntity struct {
+	Bucket  time.Time       `db:"bucket"`
+	Open    decimal.Decimal `db:"open"`
+	High    decimal.Decimal `db:"high"`
+	Low     decimal.Decimal `db:"low"`
+	Close   decimal.Decimal `db:"close"`
+	Scale   int32           `db:"scale"`
+	Samples int             `db:"samples"`
+}
@@ -0,0 +1,182 @@
+package rate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"plata/internal/domain/rate"
+	"time"
+)
+
+const (
+	rateColumns = "id, pair, rate, scale, provider, fetched_at, quote_id"
+
+	// candleAggregates summarise the quote_rates rows of one bucket.
+	candleAggregates = `
+		(array_agg(rate ORDER BY fetched_at, id))[1] AS open,
+		max(rate) AS high,
+		min(rate) AS low,
+		(array_agg(rate ORDER BY fetched_at DESC, id DESC))[1] AS close,
+		max(scale) AS scale,
+		count(*) AS samples`
+)
+
+type Repository struct {
+	dbW *sqlx.DB
+	dbR *sqlx.DB
+}
+
+func New(primary, replica *sqlx.DB) *Repository {
+	return &Repository{
+		dbW: primary,
+		dbR: replica,
+	}
+}
+
+// History returns one page of the rates matching q in chronological order.
+func (r *Repository) History(ctx context.Context, q rate.HistoryQuery) ([]*rate.Rate, error) {
+	after := rate.Cursor{At: q.From.Add(-time.Microsecond)}
+	if q.Interval > 0 {
+		// the bucket holding From may start up to one interval earlier
+		after.At = q.From.Add(-q.Interval)
+	}
+	if q.After != nil {
+		after = *q.After
+	}
+
+	var (
+		e   []entity
+		err error
+	)
+	if q.Interval > 0 {
+		const query = `
+			SELECT ` + rateColumns + `, bucket
+			FROM (
+				SELECT DISTINCT ON (bucket) ` + rateColumns + `,
+				       date_bin($4::interval, fetched_at, TIMESTAMP '2000-01-01') AS bucket
+				FROM quote_rates
+				WHERE pair = $1 AND fetched_at >= $2 AND fetched_at < $3
+				ORDER BY bucket, fetched_at DESC, id DESC
+			) b
+			WHERE bucket > $5
+			ORDER BY bucket
+			LIMIT $6
+		`
+		err = r.dbR.SelectContext(ctx, &e, query, q.Pair, q.From.UTC(), q.To.UTC(), interval(q.Interval), after.At.UTC(), q.Limit)
+	} else {
+		const query = `
+			SELECT ` + rateColumns + `
+			FROM quote_rates
+			WHERE pair = $1 AND fetched_at >= $2 AND fetched_at < $3
+			  AND (fetched_at, id) > ($4, $5)
+			ORDER BY fetched_at, id
+			LIMIT $6
+		`
+		err = r.dbR.SelectContext(ctx, &e, query, q.Pair, q.From.UTC(), q.To.UTC(), after.At.UTC(), after.ID, q.Limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rate history: %w", err)
+	}
+
+	rates := make([]*rate.Rate, len(e))
+	for i := range e {
+		rates[i] = toDomain(&e[i])
+	}
+	return rates, nil
+}
+
+// GetAt returns the last rate of pair fetched at or before t.
+func (r *Repository) GetAt(ctx context.Context, pair string, t time.Time) (*rate.Rate, error) {
+	const query = `
+		SELECT ` + rateColumns + `
+		FROM quote_rates
+		WHERE pair = $1 AND fetched_at <= $2
+		ORDER BY fetched_at DESC, id DESC
+		LIMIT 1
+	`
+	var e entity
+	if err := r.dbR.GetContext(ctx, &e, query, pair, t.UTC()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, rate.ErrRateNotFound
+		}
+		return nil, fmt.Errorf("failed to get rate at %s: %w", t.Format(time.RFC3339), err)
+	}
+	return toDomain(&e), nil
+}
+
+// Candles returns the candles of q, read from rate_candles up to its last materialized
+// bucket and aggregated from quote_rates after it.
+func (r *Repository) Candles(ctx context.Context, q rate.CandleQuery) ([]*rate.Candle, error) {
+	const query = `
+		WITH materialized AS (
+			SELECT max(bucket) + $4::interval AS until
+			FROM rate_candles
+			WHERE pair = $1 AND interval_seconds = $5
+		)
+		SELECT bucket, open, high, low, close, scale, samples
+		FROM rate_candles
+		WHERE pair = $1 AND interval_seconds = $5
+		  AND bucket >= date_bin($4::interval, $2, TIMESTAMP '2000-01-01') AND bucket < $3
+		UNION ALL
+		SELECT * FROM (
+			SELECT date_bin($4::interval, fetched_at, TIMESTAMP '2000-01-01') AS bucket,` + candleAggregates + `
+			FROM quote_rates, materialized
+			WHERE pair = $1
+			  AND fetched_at >= date_bin($4::interval, $2, TIMESTAMP '2000-01-01')
+			  AND fetched_at < $3::timestamp + $4::interval
+			  AND (materialized.until IS NULL OR fetched_at >= materialized.until)
+			GROUP BY 1
+		) live
+		WHERE bucket < $3
+		ORDER BY bucket
+	`
+	var e []candleEntity
+	err := r.dbR.SelectContext(ctx, &e, query, q.Pair, q.From.UTC(), q.To.UTC(), interval(q.Interval), int64(q.Interval.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query candles: %w", err)
+	}
+
+	candles := make([]*rate.Candle, len(e))
+	for i := range e {
+		candles[i] = candleToDomain(&e[i])
+	}
+	return candles, nil
+}
+
+// MaterializeCandles stores the candles of every pair for the buckets of the given
+// interval that closed before the given time. It starts again from the last stored
+// bucket, so a candle written from rates recorded late is brought up to date.
+func (r *Repository) MaterializeCandles(ctx context.Context, d time.Duration, before time.Time) (int64, error) {
+	const query = `
+		INSERT INTO rate_candles (pair, interval_seconds, bucket, open, high, low, close, scale, samples)
+		SELECT pair, $2, bucket, open, high, low, close, scale, samples
+		FROM (
+			SELECT r.pair, date_bin($1::interval, r.fetched_at, TIMESTAMP '2000-01-01') AS bucket,` + candleAggregates + `
+			FROM quote_rates r
+			LEFT JOIN (
+				SELECT pair, max(bucket) AS last
+				FROM rate_candles
+				WHERE interval_seconds = $2
+				GROUP BY pair
+			) m ON m.pair = r.pair
+			WHERE r.fetched_at < date_bin($1::interval, $3, TIMESTAMP '2000-01-01')
+			  AND (m.last IS NULL OR r.fetched_at >= m.last)
+			GROUP BY 1, 2
+		) c
+		ON CONFLICT (pair, interval_seconds, bucket) DO UPDATE
+		SET open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low,
+		    close = EXCLUDED.close, scale = EXCLUDED.scale, samples = EXCLUDED.samples
+		WHERE (rate_candles.samples, rate_candles.close) IS DISTINCT FROM (EXCLUDED.samples, EXCLUDED.close)
+	`
+	res, err := r.dbW.ExecContext(ctx, query, interval(d), int64(d.Seconds()), before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to materialize %s candles: %w", d, err)
+	}
+	return res.RowsAffected()
+}
+
+func interval(d time.Duration) string {
+	return fmt.Sprintf("%d microseconds", d.Microseconds())
+}
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"database/sql"
+	"plata/internal/domain/webhook"
+	"time"
+)
+
+func toDomain(e *entity) *webhook.Delivery {
+	return &webhook.Delivery{
+		ID:             e.ID,
+		QuoteID:        e.QuoteID,
+		URL:            e.URL,
+		Event:          e.Event,
+		Payload:        e.Payload,
+		Status:         webhook.Status(e.Status),
+		Attempts:       e.Attempts,
+		NextAttemptAt:  timePtr(e.NextAttemptAt),
+		LastError:      e.LastError.String,
+		LeaseOwner:     e.LeaseOwner.String,
+		LeaseExpiresAt: timePtr(e.LeaseExpiresAt),
+		CreatedAt:      e.CreatedAt,
+		DeliveredAt:    timePtr(e.DeliveredAt),
+	}
+}
+
+func toEntity(d *webhook.Delivery) *entity {
+	return &entity{
+		ID:             d.ID,
+		QuoteID:        d.QuoteID,
+		URL:            d.URL,
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  nullTime(d.NextAttemptAt),
+		LastError:      nullString(d.LastError),
+		LeaseOwner:     nullString(d.LeaseOwner),
+		LeaseExpiresAt: nullTime(d.LeaseExpiresAt),
+		CreatedAt:      d.CreatedAt.UTC(),
+		DeliveredAt:    nullTime(d.DeliveredAt),
+	}
+}
+
+func toAttemptDomain(e *attemptEntity) *webhook.Attempt {
+	return &webhook.Attempt{
+		DeliveryID:  e.DeliveryID,
+		Attempt:     e.Attempt,
+		StatusCode:  int(e.StatusCode.Int32),
+		Error:       e.Error.String,
+		DurationMS:  e.DurationMS,
+		AttemptedAt: e.AttemptedAt,
+	}
+}
+
+func toAttemptEntity(a *webhook.Attempt) *attemptEntity {
+	var code sql.NullInt32
+	if a.StatusCode != 0 {
+		code = sql.NullInt32{Int32: int32(a.StatusCode), Valid: true}
+	}
+	return &attemptEntity{
+		DeliveryID:  a.DeliveryID,
+		Attempt:     a.Attempt,
+		StatusCode:  code,
+		Error:       nullString(a.Error),
+		DurationMS:  a.DurationMS,
+		AttemptedAt: a.AttemptedAt.UTC(),
+	}
+}
+
+func nullString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
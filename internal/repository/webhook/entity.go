@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"database/sql"
+	"time"
+)
+
+type entity struct {
+	ID             string         `db:"id"`
+	QuoteID        string         `db:"quote_id"`
+	URL            string         `db:"url"`
+	Event          string         `db:"event"`
+	Payload        string         `db:"payload"`
+	Status         string         `db:"status"`
+	Attempts       int            `db:"attempts"`
+	NextAttemptAt  sql.NullTime   `db:"next_attempt_at"`
+	LastError      sql.NullString `db:"last_error"`
+	LeaseOwner     sql.NullString `db:"lease_owner"`
+	LeaseExpiresAt sql.NullTime   `db:"lease_expires_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+	DeliveredAt    sql.NullTime   `db:"delivered_at"`
+}
+
+type attemptEntity struct {
+	DeliveryID  string         `db:"delivery_id"`
+	Attempt     int            `db:"attempt"`
+	StatusCode  sql.NullInt32  `db:"status_code"`
+	Error       sql.NullString `db:"error"`
+	DurationMS  int64          `db:"duration_ms"`
+	AttemptedAt time.Time      `db:"attempted_at"`
+}
@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"plata/internal/domain/webhook"
+	"time"
+)
+
+const deliveryColumns = "id, quote_id, url, event, payload, status, attempts, next_attempt_at, last_error, lease_owner, lease_expires_at, created_at, delivered_at"
+
+type Repository struct {
+	dbP *sqlx.DB
+	dbR *sqlx.DB
+}
+
+func New(primary, replica *sqlx.DB) *Repository {
+	return &Repository{
+		dbP: primary,
+		dbR: replica,
+	}
+}
+
+// ClaimDue leases up to limit pending deliveries whose next attempt is due to owner,
+// rows locked or leased by other replicas are skipped.
+func (r *Repository) ClaimDue(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	const query = `
+		UPDATE webhook_deliveries
+		SET lease_owner = $1, lease_expires_at = $2
+		WHERE id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = $3
+			  AND next_attempt_at <= $4
+			  AND (lease_expires_at IS NULL OR lease_expires_at <= $4)
+			ORDER BY next_attempt_at
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+	var e []entity
+	err := r.dbP.SelectContext(ctx, &e, query, owner, now.Add(lease).UTC(), string(webhook.StatusPending), now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due deliveries: %w", err)
+	}
+	deliveries := make([]*webhook.Delivery, len(e))
+	for i := range e {
+		deliveries[i] = toDomain(&e[i])
+	}
+	return deliveries, nil
+}
+
+// Record stores the outcome of a delivery attempt and releases the lease, it fails
+// with ErrLeaseLost without logging the attempt when the lease has passed to another
+// worker or the delivery is no longer pending.
+func (r *Repository) Record(ctx context.Context, d *webhook.Delivery, attempt *webhook.Attempt) error {
+	tx, err := r.dbP.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const update = `
+		UPDATE webhook_deliveries
+		SET status = :status, attempts = :attempts, next_attempt_at = COALESCE(:next_attempt_at, next_attempt_at),
+		    last_error = :last_error, delivered_at = :delivered_at,
+		    lease_owner = NULL, lease_expires_at = NULL
+		WHERE id = :id
+		  AND status = 'pending'
+		  AND lease_owner = :lease_owner
+	`
+	res, err := tx.NamedExecContext(ctx, update, toEntity(d))
+	if err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+	if affected == 0 {
+		return webhook.ErrLeaseLost
+	}
+
+	const insert = `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at)
+		VALUES (:delivery_id, :attempt, :status_code, :error, :duration_ms, :attempted_at)
+	`
+	if _, err = tx.NamedExecContext(ctx, insert, toAttemptEntity(attempt)); err != nil {
+		return fmt.Errorf("failed to log delivery attempt: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit delivery attempt: %w", err)
+	}
+	d.LeaseOwner = ""
+	d.LeaseExpiresAt = nil
+	return nil
+}
+
+// ListByQuote returns the callbacks of a quote with their attempt log, oldest first.
+func (r *Repository) ListByQuote(ctx context.Context, quoteID string) ([]*webhook.Delivery, error) {
+	const query = `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE quote_id = $1
+		ORDER BY created_at
+	`
+	var e []entity
+	if err := r.dbR.SelectContext(ctx, &e, query, quoteID); err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	if len(e) == 0 {
+		return []*webhook.Delivery{}, nil
+	}
+
+	deliveries := make([]*webhook.Delivery, len(e))
+	byID := make(map[string]*webhook.Delivery, len(e))
+	ids := make([]string, len(e))
+	for i := range e {
+		deliveries[i] = toDomain(&e[i])
+		deliveries[i].Log = []*webhook.Attempt{}
+		byID[e[i].ID] = deliveries[i]
+		ids[i] = e[i].ID
+	}
+
+	const attempts = `
+		SELECT delivery_id, attempt, status_code, error, duration_ms, attempted_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = ANY($1::uuid[])
+		ORDER BY attempted_at
+	`
+	var a []attemptEntity
+	if err := r.dbR.SelectContext(ctx, &a, attempts, pq.StringArray(ids)); err != nil {
+		return nil, fmt.Errorf("failed to list delivery attempts: %w", err)
+	}
+	for i := range a {
+		if d, ok := byID[a[i].DeliveryID]; ok {
+			d.Log = append(d.Log, toAttemptDomain(&a[i]))
+		}
+	}
+	return deliveries, nil
+}
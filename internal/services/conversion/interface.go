@@ -0,0 +1,16 @@
+package conversion
+
+import (
+	"context"
+	"plata/internal/domain/conversion"
+	"plata/internal/domain/rate"
+	"time"
+)
+
+type RateRepository interface {
+	GetAt(ctx context.Context, pair string, t time.Time) (*rate.Rate, error)
+}
+
+type ConversionClient interface {
+	Convert(ctx context.Context, req conversion.Request) (*conversion.Conversion, error)
+}
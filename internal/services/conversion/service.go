@@ -0,0 +1,170 @@
+package conversion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"plata/internal/domain/conversion"
+	"plata/internal/domain/pair"
+	"plata/internal/domain/rate"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Service struct {
+	rates        RateRepository
+	pivots       []string
+	maxStaleness time.Duration
+	rounding     conversion.RoundingMode
+	log          log.Logger
+}
+
+func New(cfg config.ConvertConfig, rates RateRepository, log log.Logger) *Service {
+	rounding, err := conversion.ParseRoundingMode(cfg.Rounding)
+	if err != nil {
+		rounding = conversion.RoundHalfEven
+	}
+	return &Service{
+		rates:        rates,
+		pivots:       cfg.Pivots,
+		maxStaleness: cfg.MaxStaleness,
+		rounding:     rounding,
+		log:          log,
+	}
+}
+
+// Convert converts an amount with the last rates known at req.At, or now, through the
+// direct pair, its inverse or a pivot currency, rounded to the minor units of req.To.
+func (s *Service) Convert(ctx context.Context, req conversion.Request) (*conversion.Conversion, error) {
+	if !conversion.ValidCurrency(req.From) || !conversion.ValidCurrency(req.To) {
+		return nil, conversion.ErrInvalidCurrency
+	}
+	if !req.Amount.IsPositive() {
+		return nil, conversion.ErrInvalidAmount
+	}
+	if req.Rounding == "" {
+		req.Rounding = s.rounding
+	}
+	at := time.Now().UTC()
+	if req.At != nil {
+		at = req.At.UTC()
+	}
+
+	legs, err := s.resolve(ctx, req.From, req.To, at)
+	if err != nil {
+		return nil, err
+	}
+	c := &conversion.Conversion{
+		From:     req.From,
+		To:       req.To,
+		Amount:   req.Amount,
+		Rate:     decimal.NewFromInt(1),
+		Rounding: req.Rounding,
+		RateTime: at,
+		Legs:     legs,
+	}
+	for _, leg := range legs {
+		c.Rate = c.Rate.Mul(leg.Effective())
+		if leg.FetchedAt.Before(c.RateTime) {
+			c.RateTime = leg.FetchedAt
+		}
+	}
+	if len(legs) == 1 {
+		c.QuoteID = legs[0].QuoteID
+	}
+	c.Result = req.Rounding.Round(req.Amount.Mul(c.Rate), conversion.MinorUnits(req.To))
+	c.Rate = c.Rate.Round(conversion.RateScale)
+	return c, nil
+}
+
+// resolve finds the legs converting from into to: the pair itself or its inverse,
+// otherwise the first pivot both currencies have a fresh rate against. A stale rate
+// is only reported when no fresh path exists.
+func (s *Service) resolve(ctx context.Context, from, to string, at time.Time) ([]*conversion.Leg, error) {
+	if from == to {
+		return nil, nil
+	}
+	var stale error
+	leg, err := s.leg(ctx, from, to, at)
+	switch {
+	case err == nil:
+		legs := []*conversion.Leg{leg}
+		if stale = s.checkFresh(from, to, legs, at); stale == nil {
+			return legs, nil
+		}
+	case !errors.Is(err, conversion.ErrRateUnavailable):
+		return nil, err
+	}
+	for _, pivot := range s.pivots {
+		if pivot == from || pivot == to {
+			continue
+		}
+		first, err := s.leg(ctx, from, pivot, at)
+		if errors.Is(err, conversion.ErrRateUnavailable) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		second, err := s.leg(ctx, pivot, to, at)
+		if errors.Is(err, conversion.ErrRateUnavailable) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		legs := []*conversion.Leg{first, second}
+		if err = s.checkFresh(from, to, legs, at); err != nil {
+			if stale == nil {
+				stale = err
+			}
+			continue
+		}
+		return legs, nil
+	}
+	if stale != nil {
+		return nil, stale
+	}
+	return nil, fmt.Errorf("%w: %s", conversion.ErrRateUnavailable, pair.Join(from, to))
+}
+
+// checkFresh fails with ErrStaleRate when the oldest leg is older than maxStaleness at at.
+func (s *Service) checkFresh(from, to string, legs []*conversion.Leg, at time.Time) error {
+	if s.maxStaleness <= 0 {
+		return nil
+	}
+	for _, leg := range legs {
+		if age := at.Sub(leg.FetchedAt); age > s.maxStaleness {
+			return fmt.Errorf("%w: %s rate is %s old", conversion.ErrStaleRate, pair.Join(from, to), age.Round(time.Second))
+		}
+	}
+	return nil
+}
+
+func (s *Service) leg(ctx context.Context, from, to string, at time.Time) (*conversion.Leg, error) {
+	for _, inverted := range []bool{false, true} {
+		name := pair.Join(from, to)
+		if inverted {
+			name = pair.Join(to, from)
+		}
+		r, err := s.rates.GetAt(ctx, name, at)
+		if errors.Is(err, rate.ErrRateNotFound) || (err == nil && r.Rate.IsZero()) {
+			continue
+		}
+		if err != nil {
+			s.log.Errorf("Failed to get %s rate at %s: %v", name, at.Format(time.RFC3339), err)
+			return nil, err
+		}
+		return &conversion.Leg{
+			Pair:      r.Pair,
+			Rate:      r.Rate,
+			Inverted:  inverted,
+			QuoteID:   r.QuoteID,
+			FetchedAt: r.FetchedAt,
+		}, nil
+	}
+	return nil, conversion.ErrRateUnavailable
+}
@@ -0,0 +1,28 @@
+package lock
+
+import (
+	"context"
+	"plata/internal/domain/pair"
+	"plata/internal/domain/quote"
+	"plata/internal/domain/rate"
+	"time"
+)
+
+type LockRepository interface {
+	Create(ctx context.Context, l *quote.Lock) error
+	GetByIdempotencyKey(ctx context.Context, key string) (*quote.Lock, error)
+	Consume(ctx context.Context, token string, now time.Time) (*quote.Lock, error)
+}
+
+type RateRepository interface {
+	GetAt(ctx context.Context, pair string, t time.Time) (*rate.Rate, error)
+}
+
+type PairCatalog interface {
+	Get(ctx context.Context, name string) (*pair.Pair, error)
+}
+
+type LockClient interface {
+	Lock(ctx context.Context, currency string, ttl time.Duration, markupBps int, idemKey string) (*quote.Lock, error)
+	Consume(ctx context.Context, token string) (*quote.Lock, error)
+}
@@ -0,0 +1,151 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"plata/internal/domain/pair"
+	"plata/internal/domain/quote"
+	"plata/internal/domain/rate"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	repo         LockRepository
+	rates        RateRepository
+	pairs        PairCatalog
+	defaultTTL   time.Duration
+	maxTTL       time.Duration
+	maxRateAge   time.Duration
+	maxMarkupBps int
+	log          log.Logger
+}
+
+const (
+	defaultLockTTL      = 30 * time.Second
+	defaultMaxTTL       = 5 * time.Minute
+	defaultMaxMarkupBps = 500
+)
+
+func New(cfg config.LockConfig, repo LockRepository, rates RateRepository, pairs PairCatalog, log log.Logger) *Service {
+	if cfg.DefaultTTL <= 0 {
+		cfg.DefaultTTL = defaultLockTTL
+	}
+	if cfg.MaxTTL <= 0 {
+		cfg.MaxTTL = defaultMaxTTL
+	}
+	if cfg.MaxMarkupBps <= 0 {
+		cfg.MaxMarkupBps = defaultMaxMarkupBps
+	}
+	return &Service{
+		repo:         repo,
+		rates:        rates,
+		pairs:        pairs,
+		defaultTTL:   cfg.DefaultTTL,
+		maxTTL:       cfg.MaxTTL,
+		maxRateAge:   cfg.MaxRateAge,
+		maxMarkupBps: cfg.MaxMarkupBps,
+		log:          log,
+	}
+}
+
+// Lock snapshots the latest rate of currency, raised by markupBps, into a lock valid
+// for ttl (the configured default when zero). A repeated idempotency key returns the
+// lock created first, or ErrIdempotencyConflict when the terms differ.
+func (s *Service) Lock(ctx context.Context, currency string, ttl time.Duration, markupBps int, idemKey string) (*quote.Lock, error) {
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl < 0 || ttl > s.maxTTL {
+		return nil, fmt.Errorf("%w: ttl must be at most %s", quote.ErrInvalidLock, s.maxTTL)
+	}
+	if markupBps < 0 || markupBps > s.maxMarkupBps {
+		return nil, fmt.Errorf("%w: markup must be between 0 and %d bps", quote.ErrInvalidLock, s.maxMarkupBps)
+	}
+
+	p, err := s.pairs.Get(ctx, currency)
+	if err != nil && !errors.Is(err, pair.ErrPairNotFound) {
+		s.log.Errorf("Error looking up currency pair %s: %v", currency, err)
+		return nil, err
+	}
+	if p == nil || !p.Enabled {
+		return nil, quote.ErrUnsupportedCurrencyPair
+	}
+
+	if idemKey != "" {
+		existing, err := s.repo.GetByIdempotencyKey(ctx, idemKey)
+		if err != nil {
+			s.log.Errorf("Error checking lock idempotency key: %v", err)
+			return nil, err
+		}
+		if existing != nil {
+			s.log.Infof("Found existing lock with idempotency key: %s, token: %s", idemKey, existing.Token)
+			return sameTerms(existing, currency, ttl, markupBps)
+		}
+	}
+
+	now := time.Now().UTC()
+	r, err := s.rates.GetAt(ctx, currency, now)
+	if errors.Is(err, rate.ErrRateNotFound) {
+		return nil, quote.ErrRateUnavailable
+	}
+	if err != nil {
+		s.log.Errorf("Failed to get latest %s rate: %v", currency, err)
+		return nil, err
+	}
+	if age := now.Sub(r.FetchedAt); s.maxRateAge > 0 && age > s.maxRateAge {
+		return nil, fmt.Errorf("%w: latest rate is %s old", quote.ErrRateUnavailable, age.Round(time.Second))
+	}
+
+	l := &quote.Lock{
+		Token:          uuid.NewString(),
+		QuoteID:        r.QuoteID,
+		Currency:       currency,
+		MidRate:        r.Rate,
+		Rate:           quote.ApplyMarkup(r.Rate, markupBps, r.Scale),
+		Scale:          r.Scale,
+		MarkupBps:      markupBps,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(ttl),
+		IdempotencyKey: idemKey,
+	}
+	if err := s.repo.Create(ctx, l); err != nil {
+		if errors.Is(err, quote.ErrLockExists) {
+			// a concurrent request with the same key won the insert
+			existing, err := s.repo.GetByIdempotencyKey(ctx, idemKey)
+			if err != nil {
+				s.log.Errorf("Error getting lock by idempotency key: %v", err)
+				return nil, err
+			}
+			if existing != nil {
+				return sameTerms(existing, currency, ttl, markupBps)
+			}
+		}
+		s.log.Errorf("Failed to save quote lock: %v", err)
+		return nil, err
+	}
+	s.log.Infof("Quote locked: token=%s currency=%s rate=%s expires_at=%s", l.Token, currency, l.Rate, l.ExpiresAt.Format(time.RFC3339))
+	return l, nil
+}
+
+// sameTerms returns l when it was created for the same pair, ttl and markup.
+func sameTerms(l *quote.Lock, currency string, ttl time.Duration, markupBps int) (*quote.Lock, error) {
+	if l.Currency != currency || l.MarkupBps != markupBps || l.ExpiresAt.Sub(l.CreatedAt) != ttl {
+		return nil, quote.ErrIdempotencyConflict
+	}
+	return l, nil
+}
+
+// Consume uses a lock, it fails once the lock has expired or was already consumed.
+func (s *Service) Consume(ctx context.Context, token string) (*quote.Lock, error) {
+	l, err := s.repo.Consume(ctx, token, time.Now().UTC())
+	if err != nil {
+		s.log.Warnf("Failed to consume quote lock %s: %v", token, err)
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,18 @@
+package pair
+
+import (
+	"context"
+	"plata/internal/domain/pair"
+)
+
+type PairRepository interface {
+	List(ctx context.Context) ([]*pair.Pair, error)
+	Create(ctx context.Context, p *pair.Pair) error
+	SetEnabled(ctx context.Context, name string, enabled bool) (*pair.Pair, error)
+}
+
+type PairClient interface {
+	List(ctx context.Context) ([]*pair.Pair, error)
+	Add(ctx context.Context, p *pair.Pair) (*pair.Pair, error)
+	SetEnabled(ctx context.Context, name string, enabled bool) (*pair.Pair, error)
+}
@@ -0,0 +1,113 @@
+package pair
+
+import (
+	"context"
+	"fmt"
+	"plata/internal/common/log"
+	"plata/internal/domain/pair"
+	"sync"
+	"time"
+)
+
+type Service struct {
+	repo      PairRepository
+	providers map[string]struct{}
+	ttl       time.Duration
+	log       log.Logger
+
+	mu       sync.RWMutex
+	cache    map[string]*pair.Pair
+	loadedAt time.Time
+}
+
+// New creates the pair service, providers lists the names a pair may be pinned to.
+func New(repo PairRepository, providers []string, ttl time.Duration, log log.Logger) *Service {
+	known := make(map[string]struct{}, len(providers))
+	for _, name := range providers {
+		known[name] = struct{}{}
+	}
+	return &Service{
+		repo:      repo,
+		providers: known,
+		ttl:       ttl,
+		log:       log,
+	}
+}
+
+func (s *Service) List(ctx context.Context) ([]*pair.Pair, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *Service) Add(ctx context.Context, p *pair.Pair) (*pair.Pair, error) {
+	if err := pair.Validate(p); err != nil {
+		return nil, err
+	}
+	if _, ok := s.providers[p.Provider]; p.Provider != "" && !ok {
+		return nil, fmt.Errorf("%w: %s", pair.ErrUnknownProvider, p.Provider)
+	}
+	p.CreatedAt = time.Now()
+	s.log.Infof("Adding currency pair: %+v", p)
+	if err := s.repo.Create(ctx, p); err != nil {
+		s.log.Errorf("Failed to add currency pair %s: %v", p.Pair, err)
+		return nil, err
+	}
+	s.invalidate()
+	return p, nil
+}
+
+func (s *Service) SetEnabled(ctx context.Context, name string, enabled bool) (*pair.Pair, error) {
+	s.log.Infof("Setting currency pair %s enabled=%t", name, enabled)
+	p, err := s.repo.SetEnabled(ctx, name, enabled)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return p, nil
+}
+
+// Get returns a pair from the cached catalogue, reloading it once the TTL has passed.
+func (s *Service) Get(ctx context.Context, name string) (*pair.Pair, error) {
+	pairs, err := s.catalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := pairs[name]
+	if !ok {
+		return nil, pair.ErrPairNotFound
+	}
+	return p, nil
+}
+
+func (s *Service) catalogue(ctx context.Context) (map[string]*pair.Pair, error) {
+	s.mu.RLock()
+	if s.cache != nil && time.Since(s.loadedAt) < s.ttl {
+		cache := s.cache
+		s.mu.RUnlock()
+		return cache, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cache != nil && time.Since(s.loadedAt) < s.ttl {
+		return s.cache, nil
+	}
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		s.log.Errorf("Failed to load currency pairs: %v", err)
+		return nil, err
+	}
+	cache := make(map[string]*pair.Pair, len(list))
+	for _, p := range list {
+		cache[p.Pair] = p
+	}
+	s.cache = cache
+	s.loadedAt = time.Now()
+	return cache, nil
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
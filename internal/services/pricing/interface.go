@@ -0,0 +1,22 @@
+package pricing
+
+import (
+	"context"
+	"plata/internal/domain/pricing"
+	"plata/internal/domain/quote"
+
+	"github.com/shopspring/decimal"
+)
+
+type RuleRepository interface {
+	List(ctx context.Context) ([]*pricing.Rule, error)
+	Create(ctx context.Context, r *pricing.Rule) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type PricingClient interface {
+	List(ctx context.Context) ([]*pricing.Rule, error)
+	Add(ctx context.Context, r *pricing.Rule) (*pricing.Rule, error)
+	Delete(ctx context.Context, id int64) error
+	Price(ctx context.Context, q *quote.Quote, tenant string, amount decimal.Decimal) (*quote.Quote, error)
+}
@@ -0,0 +1,110 @@
+package pricing
+
+import (
+	"context"
+	"plata/internal/common/log"
+	"plata/internal/domain/pricing"
+	"plata/internal/domain/quote"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Service struct {
+	repo RuleRepository
+	ttl  time.Duration
+	log  log.Logger
+
+	mu       sync.RWMutex
+	cache    []*pricing.Rule
+	loadedAt time.Time
+}
+
+func New(repo RuleRepository, ttl time.Duration, log log.Logger) *Service {
+	return &Service{
+		repo: repo,
+		ttl:  ttl,
+		log:  log,
+	}
+}
+
+func (s *Service) List(ctx context.Context) ([]*pricing.Rule, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *Service) Add(ctx context.Context, r *pricing.Rule) (*pricing.Rule, error) {
+	if err := pricing.Validate(r); err != nil {
+		return nil, err
+	}
+	r.CreatedAt = time.Now()
+	s.log.Infof("Adding pricing rule: %+v", r)
+	if err := s.repo.Create(ctx, r); err != nil {
+		s.log.Errorf("Failed to add pricing rule: %v", err)
+		return nil, err
+	}
+	s.invalidate()
+	return r, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	s.log.Infof("Deleting pricing rule %d", id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Price returns a copy of a done quote with the bid and ask of the rule applying to
+// tenant and an amount of the base currency; other quotes are returned as is.
+func (s *Service) Price(ctx context.Context, q *quote.Quote, tenant string, amount decimal.Decimal) (*quote.Quote, error) {
+	if q.Status != quote.StatusDone {
+		return q, nil
+	}
+	if amount.IsNegative() {
+		return nil, pricing.ErrInvalidAmount
+	}
+	rules, err := s.rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rule := pricing.Select(rules, tenant, q.Currency, amount)
+	priced := *q
+	priced.Price = pricing.Apply(rule, q.Currency, q.Amount, q.Scale)
+	return &priced, nil
+}
+
+// rules returns the cached pricing rules, reloading them once the TTL has passed.
+func (s *Service) rules(ctx context.Context) ([]*pricing.Rule, error) {
+	s.mu.RLock()
+	if s.cache != nil && time.Since(s.loadedAt) < s.ttl {
+		cache := s.cache
+		s.mu.RUnlock()
+		return cache, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cache != nil && time.Since(s.loadedAt) < s.ttl {
+		return s.cache, nil
+	}
+	rules, err := s.repo.List(ctx)
+	if err != nil {
+		s.log.Errorf("Failed to load pricing rules: %v", err)
+		return nil, err
+	}
+	if rules == nil {
+		rules = []*pricing.Rule{}
+	}
+	s.cache = rules
+	s.loadedAt = time.Now()
+	return rules, nil
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
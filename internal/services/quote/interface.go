@@ -2,20 +2,41 @@ package quote
 
 import (
 	"context"
+	"plata/internal/domain/pair"
 	"plata/internal/domain/quote"
+	"time"
 )
 
 type QuoteRepository interface {
 	Save(ctx context.Context, q *quote.Quote) error
 	GetByID(ctx context.Context, id string) (*quote.Quote, error)
 	GetLatestByCurrency(ctx context.Context, currency string) (*quote.Quote, error)
+	GetPendingByCurrency(ctx context.Context, currency string) (*quote.Quote, error)
 	Update(ctx context.Context, q *quote.Quote) error
 	GetByIdempotencyKey(ctx context.Context, key string) (*quote.Quote, error)
-	GetInProgressQuotes(ctx context.Context) ([]*quote.Quote, error)
+	GetInProgressQuotes(ctx context.Context, now time.Time) ([]*quote.Quote, error)
+	ListDoneAfter(ctx context.Context, currencies []string, afterID string, limit int) ([]*quote.Quote, error)
+}
+
+// UpdateSubscriber delivers quotes reaching a terminal status, the channel is closed
+// when the subscriber falls behind or cancel is called.
+type UpdateSubscriber interface {
+	Subscribe(filter func(q *quote.Quote) bool, buffer int) (updates <-chan *quote.Quote, cancel func())
+}
+
+type CallbackValidator interface {
+	CheckCallback(rawURL string) error
+}
+
+type PairCatalog interface {
+	Get(ctx context.Context, name string) (*pair.Pair, error)
 }
 
 type QuoteClient interface {
-	RequestUpdate(ctx context.Context, currency, idemKey string) (string, error)
+	RequestUpdate(ctx context.Context, currency, idemKey, callbackURL string) (string, error)
 	GetByID(ctx context.Context, id string) (*quote.Quote, error)
+	WaitByID(ctx context.Context, id string, timeout time.Duration) (*quote.Quote, error)
+	Stream(ctx context.Context, currencies []string, lastEventID string, buffer int) (<-chan *quote.Quote, error)
 	GetLatestByCurrency(ctx context.Context, currency string) (*quote.Quote, error)
+	GetLatest(ctx context.Context, currency string, maxAge time.Duration, refresh bool) (*quote.Latest, error)
 }
@@ -2,36 +2,68 @@ package quote
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"plata/internal/common/log"
+	"plata/internal/domain/pair"
+	"plata/internal/domain/webhook"
 
 	"plata/internal/clients/exchange"
 	"plata/internal/domain/quote"
 	"time"
 )
 
+// refreshWindow bounds refreshes requested by GetLatest to one per pair and window.
+const refreshWindow = time.Minute
+
+// refreshNamespace derives the idempotency keys of refreshes requested by GetLatest.
+var refreshNamespace = uuid.MustParse("6f1c2a8e-4b7d-4e52-9a63-0d9e5c7b1f24")
+
 type Service struct {
-	repo    QuoteRepository
-	fetcher exchange.ExternalRateFetcher
-	log     log.Logger
+	repo      QuoteRepository
+	pairs     PairCatalog
+	fetcher   exchange.ExternalRateFetcher
+	updates   UpdateSubscriber
+	callbacks CallbackValidator
+	log       log.Logger
 }
 
 func New(
 	repo QuoteRepository,
+	pairs PairCatalog,
 	fetcher exchange.ExternalRateFetcher,
+	updates UpdateSubscriber,
+	callbacks CallbackValidator,
 	log log.Logger,
 ) *Service {
 	return &Service{
-		repo:    repo,
-		fetcher: fetcher,
-		log:     log,
+		repo:      repo,
+		pairs:     pairs,
+		fetcher:   fetcher,
+		updates:   updates,
+		callbacks: callbacks,
+		log:       log,
 	}
 }
 
-func (s *Service) RequestUpdate(ctx context.Context, currency, idemKey string) (string, error) {
+func (s *Service) RequestUpdate(ctx context.Context, currency, idemKey, callbackURL string) (string, error) {
 	s.log.Infof("RequestUpdate called with currency: %s, idempotency key: %s", currency, idemKey)
 
-	if _, ok := quote.AllowedPairs[currency]; !ok {
+	if callbackURL != "" {
+		if s.callbacks == nil {
+			return "", webhook.ErrCallbackNotAllowed
+		}
+		if err := s.callbacks.CheckCallback(callbackURL); err != nil {
+			return "", err
+		}
+	}
+
+	p, err := s.pairs.Get(ctx, currency)
+	if err != nil && !errors.Is(err, pair.ErrPairNotFound) {
+		s.log.Errorf("Error looking up currency pair %s: %v", currency, err)
+		return "", err
+	}
+	if p == nil || !p.Enabled {
 		s.log.Warnf("Unsupported currency pair: %s", currency)
 		return "", quote.ErrUnsupportedCurrencyPair
 	}
@@ -50,16 +82,31 @@ func (s *Service) RequestUpdate(ctx context.Context, currency, idemKey string) (
 	}
 
 	id := uuid.NewString()
+	now := time.Now()
 	q := &quote.Quote{
 		ID:             id,
 		Currency:       currency,
+		Scale:          int32(p.Precision),
 		Status:         quote.StatusInProgress,
-		UpdatedAt:      time.Now(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
 		IdempotencyKey: idemKey,
+		CallbackURL:    callbackURL,
 	}
 
-	s.log.Infof("Saving new quote: %+v", q)
+	s.log.Infof("Saving new quote: id=%s currency=%s callback=%t", q.ID, q.Currency, q.CallbackURL != "")
 	if err := s.repo.Save(ctx, q); err != nil {
+		if errors.Is(err, quote.ErrQuoteExists) {
+			// a concurrent request with the same key won the insert
+			existing, err := s.repo.GetByIdempotencyKey(ctx, idemKey)
+			if err != nil {
+				s.log.Errorf("Error getting quote by idempotency key: %v", err)
+				return "", err
+			}
+			if existing != nil {
+				return existing.ID, nil
+			}
+		}
 		s.log.Errorf("Failed to save quote: %v", err)
 		return "", err
 	}
@@ -72,6 +119,76 @@ func (s *Service) GetByID(ctx context.Context, id string) (*quote.Quote, error)
 	return s.repo.GetByID(ctx, id)
 }
 
+// WaitByID returns the quote once it reaches a terminal status or, when timeout
+// elapses first, in its current state.
+func (s *Service) WaitByID(ctx context.Context, id string, timeout time.Duration) (*quote.Quote, error) {
+	if timeout <= 0 || s.updates == nil {
+		return s.repo.GetByID(ctx, id)
+	}
+	// Subscribe before reading so an update landing in between is not missed.
+	updates, cancel := s.updates.Subscribe(func(q *quote.Quote) bool {
+		return q.ID == id && q.Status.IsTerminal()
+	}, 1)
+	defer cancel()
+
+	q, err := s.repo.GetByID(ctx, id)
+	if err != nil || q.Status.IsTerminal() {
+		return q, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case updated, ok := <-updates:
+		if ok {
+			return updated, nil
+		}
+		return s.repo.GetByID(ctx, id)
+	case <-timer.C:
+		return s.repo.GetByID(ctx, id)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func (s *Service) GetLatestByCurrency(ctx context.Context, currency string) (*quote.Quote, error) {
 	return s.repo.GetLatestByCurrency(ctx, currency)
 }
+
+// GetLatest returns the newest done quote of a pair with its age, stale when older
+// than maxAge. With refresh an update is requested for a stale or missing quote,
+// reusing one already in progress.
+func (s *Service) GetLatest(ctx context.Context, currency string, maxAge time.Duration, refresh bool) (*quote.Latest, error) {
+	q, err := s.repo.GetLatestByCurrency(ctx, currency)
+	if err != nil && (!errors.Is(err, quote.ErrQuoteNotFound) || !refresh) {
+		return nil, err
+	}
+	latest := &quote.Latest{Quote: q}
+	if q != nil {
+		age := time.Since(q.UpdatedAt)
+		q.Age = &age
+		latest.Stale = maxAge > 0 && age > maxAge
+	}
+	if refresh && (q == nil || latest.Stale) {
+		if latest.RefreshID, err = s.refresh(ctx, currency); err != nil {
+			return nil, err
+		}
+	}
+	return latest, nil
+}
+
+// refresh reuses an update in progress for currency, otherwise requests one keyed by
+// currency and refreshWindow so concurrent callers share it.
+func (s *Service) refresh(ctx context.Context, currency string) (string, error) {
+	pending, err := s.repo.GetPendingByCurrency(ctx, currency)
+	if err != nil {
+		s.log.Errorf("Failed to look up pending %s update: %v", currency, err)
+		return "", err
+	}
+	if pending != nil {
+		return pending.ID, nil
+	}
+	window := time.Now().UTC().Truncate(refreshWindow)
+	key := uuid.NewSHA1(refreshNamespace, []byte(currency+"@"+window.Format(time.RFC3339))).String()
+	s.log.Infof("Requesting refresh of stale quote: %s", currency)
+	return s.RequestUpdate(ctx, currency, key, "")
+}
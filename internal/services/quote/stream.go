@@ -0,0 +1,79 @@
+package quote
+
+import (
+	"context"
+	"plata/internal/domain/quote"
+	"slices"
+)
+
+// streamReplayPage is how many missed quotes are read at once while replaying.
+const streamReplayPage = 1000
+
+// Stream delivers done quotes of the given pairs until ctx is done. With lastEventID the
+// quotes written after it are replayed first, page by page. The channel is closed when
+// the consumer falls more than buffer updates behind or a replay page cannot be read,
+// it should resume with the last quote received.
+func (s *Service) Stream(ctx context.Context, currencies []string, lastEventID string, buffer int) (<-chan *quote.Quote, error) {
+	if s.updates == nil {
+		return nil, quote.ErrStreamUnavailable
+	}
+	updates, cancel := s.updates.Subscribe(func(q *quote.Quote) bool {
+		return q.Status == quote.StatusDone && slices.Contains(currencies, q.Currency)
+	}, buffer)
+
+	var replay []*quote.Quote
+	if lastEventID != "" {
+		var err error
+		replay, err = s.repo.ListDoneAfter(ctx, currencies, lastEventID, streamReplayPage)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	out := make(chan *quote.Quote)
+	go func() {
+		defer close(out)
+		defer cancel()
+		replayed := make(map[string]struct{}, len(replay))
+		for len(replay) > 0 {
+			for _, q := range replay {
+				replayed[q.ID] = struct{}{}
+				select {
+				case out <- q:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(replay) < streamReplayPage {
+				break
+			}
+			var err error
+			replay, err = s.repo.ListDoneAfter(ctx, currencies, replay[len(replay)-1].ID, streamReplayPage)
+			if err != nil {
+				s.log.Errorf("Failed to replay missed quotes, disconnecting: %v", err)
+				return
+			}
+		}
+		for {
+			select {
+			case q, ok := <-updates:
+				if !ok {
+					s.log.Warnf("Quote stream consumer fell behind, disconnecting: currencies=%v", currencies)
+					return
+				}
+				if _, ok := replayed[q.ID]; ok {
+					continue
+				}
+				select {
+				case out <- q:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
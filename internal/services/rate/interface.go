@@ -0,0 +1,19 @@
+package rate
+
+import (
+	"context"
+	"plata/internal/domain/rate"
+	"time"
+)
+
+type RateRepository interface {
+	History(ctx context.Context, q rate.HistoryQuery) ([]*rate.Rate, error)
+	GetAt(ctx context.Context, pair string, t time.Time) (*rate.Rate, error)
+	Candles(ctx context.Context, q rate.CandleQuery) ([]*rate.Candle, error)
+}
+
+type RateClient interface {
+	History(ctx context.Context, q rate.HistoryQuery) (*rate.Page, error)
+	GetRateAt(ctx context.Context, pair string, t time.Time) (*rate.Rate, error)
+	Candles(ctx context.Context, q rate.CandleQuery) (*rate.CandleSeries, error)
+}
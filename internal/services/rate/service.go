@@ -0,0 +1,62 @@
+package rate
+
+import (
+	"context"
+	"plata/internal/common/log"
+	"plata/internal/domain/rate"
+	"time"
+)
+
+type Service struct {
+	repo RateRepository
+	log  log.Logger
+}
+
+func New(repo RateRepository, log log.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// History returns a page of the rate history of a pair, NextCursor is set while more
+// rates may follow.
+func (s *Service) History(ctx context.Context, q rate.HistoryQuery) (*rate.Page, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	rates, err := s.repo.History(ctx, q)
+	if err != nil {
+		s.log.Errorf("Failed to query %s rate history: %v", q.Pair, err)
+		return nil, err
+	}
+
+	page := &rate.Page{Items: rates}
+	if len(rates) == q.Limit {
+		last := rates[len(rates)-1]
+		next := rate.Cursor{At: last.FetchedAt, ID: last.ID}
+		if last.Bucket != nil {
+			next = rate.Cursor{At: *last.Bucket}
+		}
+		page.NextCursor = next.Encode()
+	}
+	return page, nil
+}
+
+// Candles returns the OHLC candles of a pair, one per bucket holding at least one rate.
+func (s *Service) Candles(ctx context.Context, q rate.CandleQuery) (*rate.CandleSeries, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	candles, err := s.repo.Candles(ctx, q)
+	if err != nil {
+		s.log.Errorf("Failed to query %s candles: %v", q.Pair, err)
+		return nil, err
+	}
+	return &rate.CandleSeries{Pair: q.Pair, Interval: q.Interval.String(), Candles: candles}, nil
+}
+
+// GetRateAt returns the last known rate of a pair at t.
+func (s *Service) GetRateAt(ctx context.Context, pair string, t time.Time) (*rate.Rate, error) {
+	return s.repo.GetAt(ctx, pair, t.UTC())
+}
@@ -0,0 +1,15 @@
+package webhook
+
+import (
+	"context"
+	"plata/internal/domain/webhook"
+)
+
+type DeliveryRepository interface {
+	ListByQuote(ctx context.Context, quoteID string) ([]*webhook.Delivery, error)
+}
+
+type WebhookClient interface {
+	CheckCallback(rawURL string) error
+	ListByQuote(ctx context.Context, quoteID string) ([]*webhook.Delivery, error)
+}
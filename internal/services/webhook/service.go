@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"context"
+	"plata/internal/common/log"
+	"plata/internal/domain/webhook"
+)
+
+type Service struct {
+	repo    DeliveryRepository
+	allowed webhook.AllowList
+	log     log.Logger
+}
+
+func New(repo DeliveryRepository, allowed []string, log log.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		allowed: allowed,
+		log:     log,
+	}
+}
+
+// CheckCallback validates a callback URL against the configured host allow-list.
+func (s *Service) CheckCallback(rawURL string) error {
+	if err := s.allowed.Check(rawURL); err != nil {
+		s.log.Warnf("Rejected callback URL %q: %v", rawURL, err)
+		return err
+	}
+	return nil
+}
+
+// ListByQuote returns the delivery log of the callbacks sent for a quote.
+func (s *Service) ListByQuote(ctx context.Context, quoteID string) ([]*webhook.Delivery, error) {
+	return s.repo.ListByQuote(ctx, quoteID)
+}
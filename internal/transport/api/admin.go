@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	dp "plata/internal/domain/pair"
+)
+
+// ListPairs returns the currency pair catalogue
+// @Summary List currency pairs
+// @Tags admin
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]dp.Pair}
+// @Failure 500 {object} ErrorResponse "Error response"
+// @Router /admin/pairs [get]
+func (h *Handler) ListPairs(c *gin.Context) {
+	pairs, err := h.PairService.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "currency pairs retrieved",
+		Data:    pairs,
+	})
+}
+
+// AddPair adds a currency pair to the catalogue
+// @Summary Add currency pair
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body AddPairRequest true "Currency pair"
+// @Success 201 {object} SuccessResponse{data=dp.Pair}
+// @Failure 400,409,500 {object} ErrorResponse "Error response"
+// @Router /admin/pairs [post]
+func (h *Handler) AddPair(c *gin.Context) {
+	var req AddPairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+	p := &dp.Pair{
+		Pair:      req.Pair,
+		Enabled:   true,
+		Precision: dp.DefaultPrecision,
+		Provider:  req.Provider,
+	}
+	if req.Enabled != nil {
+		p.Enabled = *req.Enabled
+	}
+	if req.Precision != nil {
+		p.Precision = *req.Precision
+	}
+	p, err := h.PairService.Add(c.Request.Context(), p)
+	if err != nil {
+		switch {
+		case errors.Is(err, dp.ErrInvalidPair), errors.Is(err, dp.ErrInvalidPrecision), errors.Is(err, dp.ErrUnknownProvider):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "invalid currency pair",
+				Details: err.Error(),
+			})
+		case errors.Is(err, dp.ErrPairExists):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Status:  http.StatusConflict,
+				Error:   "currency pair already exists",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status:  http.StatusInternalServerError,
+				Error:   "failed to add currency pair",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Status:  http.StatusCreated,
+		Message: "currency pair added",
+		Data:    p,
+	})
+}
+
+// EnablePair enables a currency pair
+// @Summary Enable currency pair
+// @Tags admin
+// @Produce json
+// @Param base path string true "Base currency" example(EUR)
+// @Param target path string true "Quote currency" example(USD)
+// @Success 200 {object} SuccessResponse{data=dp.Pair}
+// @Failure 404,500 {object} ErrorResponse "Error response"
+// @Router /admin/pairs/{base}/{target}/enable [post]
+func (h *Handler) EnablePair(c *gin.Context) {
+	h.setPairEnabled(c, true)
+}
+
+// DisablePair disables a currency pair
+// @Summary Disable currency pair
+// @Tags admin
+// @Produce json
+// @Param base path string true "Base currency" example(EUR)
+// @Param target path string true "Quote currency" example(USD)
+// @Success 200 {object} SuccessResponse{data=dp.Pair}
+// @Failure 404,500 {object} ErrorResponse "Error response"
+// @Router /admin/pairs/{base}/{target}/disable [post]
+func (h *Handler) DisablePair(c *gin.Context) {
+	h.setPairEnabled(c, false)
+}
+
+func (h *Handler) setPairEnabled(c *gin.Context, enabled bool) {
+	name := dp.Join(c.Param("base"), c.Param("target"))
+	p, err := h.PairService.SetEnabled(c.Request.Context(), name, enabled)
+	if err != nil {
+		if errors.Is(err, dp.ErrPairNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Status:  http.StatusNotFound,
+				Error:   "currency pair not found",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "failed to update currency pair",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "currency pair updated",
+		Data:    p,
+	})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	dq "plata/internal/domain/quote"
+	dw "plata/internal/domain/webhook"
+)
+
+// ListCallbacks returns the callback delivery log of a quote
+// @Summary List quote callbacks
+// @Description Deliveries of the callback_url given when requesting the update, with every attempt made
+// @Tags quotes
+// @Produce json
+// @Param id path string true "Quote update ID"
+// @Success 200 {object} SuccessResponse{data=[]dw.Delivery}
+// @Failure 400,404,500 {object} ErrorResponse "Error response"
+// @Router /quotes/{id}/callbacks [get]
+func (h *Handler) ListCallbacks(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid ID format, must be a UUID",
+			Details: err.Error(),
+		})
+		return
+	}
+	if _, err := h.QuoteService.GetByID(c.Request.Context(), id); err != nil {
+		if errors.Is(err, dq.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Status:  http.StatusNotFound,
+				Error:   "unable to find quote with such id",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+	deliveries, err := h.WebhookService.ListByQuote(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*dw.Delivery{}
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "callback deliveries retrieved",
+		Data:    deliveries,
+	})
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	dc "plata/internal/domain/conversion"
+)
+
+// Convert converts an amount between two currencies
+// @Summary Convert an amount
+// @Description Converts with the latest rates, or the last rates known at at. Pairs are used directly, inverted or triangulated through a pivot currency.
+// @Description The result is rounded to the minor units of the target currency; rates older than the staleness limit are rejected with 409.
+// @Tags rates
+// @Produce json
+// @Param from query string true "Source currency, e.g. EUR"
+// @Param to query string true "Target currency, e.g. MXN"
+// @Param amount query string true "Amount to convert, e.g. 125.40"
+// @Param at query string false "RFC3339 time of the rates to use, defaults to now"
+// @Param rounding query string false "half_even (default), half_up, up, down, ceiling or floor"
+// @Success 200 {object} SuccessResponse{data=dc.Conversion}
+// @Failure 400,404,409,500 {object} ErrorResponse "Error response"
+// @Router /convert [get]
+func (h *Handler) Convert(c *gin.Context) {
+	var req ConvertRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+	r, err := req.request()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+	conv, err := h.ConversionService.Convert(c.Request.Context(), r)
+	if err != nil {
+		switch {
+		case errors.Is(err, dc.ErrInvalidCurrency), errors.Is(err, dc.ErrInvalidAmount):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "invalid request",
+				Details: err.Error(),
+			})
+		case errors.Is(err, dc.ErrRateUnavailable):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Status:  http.StatusNotFound,
+				Error:   "no rate available for this conversion",
+				Details: err.Error(),
+			})
+		case errors.Is(err, dc.ErrStaleRate):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Status:  http.StatusConflict,
+				Error:   "rate is too old",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status:  http.StatusInternalServerError,
+				Error:   "internal error",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "amount converted",
+		Data:    conv,
+	})
+}
+
+func (r ConvertRequest) request() (dc.Request, error) {
+	req := dc.Request{From: r.From, To: r.To}
+	var err error
+	if req.Amount, err = decimal.NewFromString(r.Amount); err != nil {
+		return req, dc.ErrInvalidAmount
+	}
+	if r.At != "" {
+		at, err := time.Parse(time.RFC3339, r.At)
+		if err != nil {
+			return req, err
+		}
+		req.At = &at
+	}
+	if r.Rounding != "" {
+		if req.Rounding, err = dc.ParseRoundingMode(r.Rounding); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
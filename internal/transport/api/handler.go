@@ -1,21 +1,96 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"github.com/google/uuid"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"plata/internal/app/leader"
+	"plata/internal/config"
 	dq "plata/internal/domain/quote"
+	dw "plata/internal/domain/webhook"
+	"plata/internal/services/conversion"
+	"plata/internal/services/lock"
+	"plata/internal/services/pair"
+	"plata/internal/services/pricing"
 	"plata/internal/services/quote"
+	"plata/internal/services/rate"
+	"plata/internal/services/webhook"
 )
 
 type Handler struct {
-	QuoteService quote.QuoteClient
+	QuoteService      quote.QuoteClient
+	PairService       pair.PairClient
+	WebhookService    webhook.WebhookClient
+	RateService       rate.RateClient
+	ConversionService conversion.ConversionClient
+	LockService       lock.LockClient
+	PricingService    pricing.PricingClient
+	Leader            leader.StatusClient
+	// MaxWait caps the ?wait= long-poll of GetQuoteByID.
+	MaxWait         time.Duration
+	StreamHeartbeat time.Duration
+	StreamBuffer    int
+	// LatestRefresh requests an update when GetLatestQuote finds a stale or missing quote.
+	LatestRefresh bool
+
+	// streams is cancelled by CloseStreams to end the open quote streams on shutdown.
+	streams      context.Context
+	closeStreams context.CancelFunc
+}
+
+const (
+	defaultMaxWait         = 30 * time.Second
+	defaultStreamHeartbeat = 15 * time.Second
+	defaultStreamBuffer    = 64
+)
+
+func NewHandler(
+	quoteService quote.QuoteClient,
+	pairService pair.PairClient,
+	webhookService webhook.WebhookClient,
+	rateService rate.RateClient,
+	conversionService conversion.ConversionClient,
+	lockService lock.LockClient,
+	pricingService pricing.PricingClient,
+	leader leader.StatusClient,
+	cfg config.ServerConfig,
+) *Handler {
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = defaultMaxWait
+	}
+	if cfg.StreamHeartbeat <= 0 {
+		cfg.StreamHeartbeat = defaultStreamHeartbeat
+	}
+	if cfg.StreamBuffer <= 0 {
+		cfg.StreamBuffer = defaultStreamBuffer
+	}
+	streams, closeStreams := context.WithCancel(context.Background())
+	return &Handler{
+		QuoteService:      quoteService,
+		PairService:       pairService,
+		WebhookService:    webhookService,
+		RateService:       rateService,
+		ConversionService: conversionService,
+		LockService:       lockService,
+		PricingService:    pricingService,
+		Leader:            leader,
+		MaxWait:           cfg.MaxWait,
+		StreamHeartbeat:   cfg.StreamHeartbeat,
+		StreamBuffer:      cfg.StreamBuffer,
+		LatestRefresh:     cfg.LatestRefresh,
+		streams:           streams,
+		closeStreams:      closeStreams,
+	}
 }
 
-func NewHandler(quoteService quote.QuoteClient) *Handler {
-	return &Handler{QuoteService: quoteService}
+// CloseStreams ends the open quote streams, the server waits for them on shutdown
+// since their requests never finish on their own.
+func (h *Handler) CloseStreams() {
+	h.closeStreams()
 }
 
 type ErrorResponse struct {
@@ -32,7 +107,8 @@ type SuccessResponse struct {
 
 // UpdateQuote updates a currency pair quote
 // @Summary Update a quote
-// @Description Asynchronously request a quote update for a currency pair
+// @Description Asynchronously request a quote update for a currency pair.
+// @Description An optional callback_url receives an HMAC-SHA256 signed POST (X-Plata-Signature) when the update finishes.
 // @Tags quotes
 // @Accept json
 // @Produce json
@@ -58,8 +134,16 @@ func (h *Handler) UpdateQuote(c *gin.Context) {
 		})
 		return
 	}
-	id, err := h.QuoteService.RequestUpdate(c.Request.Context(), req.Currency, idemKey)
+	id, err := h.QuoteService.RequestUpdate(c.Request.Context(), req.Currency, idemKey, req.CallbackURL)
 	if err != nil {
+		if errors.Is(err, dw.ErrInvalidCallbackURL) || errors.Is(err, dw.ErrCallbackNotAllowed) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "callback_url is not accepted",
+				Details: err.Error(),
+			})
+			return
+		}
 		if errors.Is(err, dq.ErrUnsupportedCurrencyPair) {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
 				Status:  http.StatusBadRequest,
@@ -84,9 +168,16 @@ func (h *Handler) UpdateQuote(c *gin.Context) {
 
 // GetQuoteByID retrieves a quote by update ID
 // @Summary Retrieve quote by ID
+// @Description Status is in_progress until the update reaches a terminal state: done, failed or expired.
+// @Description With wait (e.g. 10s, capped by the server) the request blocks until the quote is terminal or the time elapses.
+// @Description Done quotes carry mid, bid, ask and the rule_id of the pricing rule matching the X-Tenant-ID header and amount.
+// @Description X-Tenant-ID is trusted as sent: the gateway in front of the service must authenticate the client and set it.
 // @Tags quotes
 // @Produce json
 // @Param id path string true "Quote update ID"
+// @Param wait query string false "Maximum time to wait for a terminal status, e.g. 10s"
+// @Param X-Tenant-ID header string false "Tenant the quote is priced for, set by the authenticating gateway"
+// @Param amount query string false "Amount of the base currency selecting the pricing tier"
 // @Success 200 {object} SuccessResponse{data=dq.Quote}
 // @Failure 400,404,500 {object} ErrorResponse "Error response"
 // @Router /quotes/{id} [get]
@@ -100,7 +191,19 @@ func (h *Handler) GetQuoteByID(c *gin.Context) {
 		})
 		return
 	}
-	q, err := h.QuoteService.GetByID(c.Request.Context(), id)
+	var wait time.Duration
+	if raw := c.Query("wait"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: http.StatusBadRequest,
+				Error:  "invalid wait duration, e.g. 10s",
+			})
+			return
+		}
+		wait = min(d, h.MaxWait)
+	}
+	q, err := h.QuoteService.WaitByID(c.Request.Context(), id, wait)
 	if err != nil {
 		if errors.Is(err, dq.ErrQuoteNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
@@ -117,6 +220,10 @@ func (h *Handler) GetQuoteByID(c *gin.Context) {
 		})
 		return
 	}
+	q, ok := h.priceQuote(c, q)
+	if !ok {
+		return
+	}
 	c.JSON(http.StatusOK, SuccessResponse{
 		Status:  http.StatusOK,
 		Message: "quote found",
@@ -126,45 +233,98 @@ func (h *Handler) GetQuoteByID(c *gin.Context) {
 
 // GetLatestQuote returns the latest quote for a given currency pair
 // @Summary Get latest quote
-// @Description Returns the most recent quote for a currency pair (e.g. EUR/USD)
+// @Description Returns the most recent done quote for a currency pair (e.g. EUR/USD) with its age_seconds.
+// @Description With max_age (e.g. 5m) an older quote returns 409, or 202 with the update_id of a refresh when refresh is on (server default, or ?refresh=).
+// @Description A missing quote is also refreshed with 202 when refresh is on.
+// @Description Done quotes carry mid, bid, ask and the rule_id of the pricing rule matching the X-Tenant-ID header and amount.
+// @Description X-Tenant-ID is trusted as sent: the gateway in front of the service must authenticate the client and set it.
 // @Tags quotes
 // @Accept json
 // @Produce json
-// @Param currency query string true "Currency pair" Enums(EUR/USD, EUR/MXN, EUR/RUB)
+// @Param currency query string true "Currency pair"
+// @Param max_age query string false "Maximum age of the quote, e.g. 5m"
+// @Param refresh query bool false "Request an update when the quote is stale or missing"
+// @Param X-Tenant-ID header string false "Tenant the quote is priced for, set by the authenticating gateway"
+// @Param amount query string false "Amount of the base currency selecting the pricing tier"
 // @Success 200 {object} SuccessResponse{data=dq.Quote}
-// @Failure 400,404,500 {object} ErrorResponse "Error response"
+// @Success 202 {object} SuccessResponse{data=RefreshQuoteResponse}
+// @Failure 400,404,409,500 {object} ErrorResponse "Error response"
 // @Router /quotes/latest [get]
 func (h *Handler) GetLatestQuote(c *gin.Context) {
-	currency := c.Query("currency")
-	if currency == "" {
+	var req LatestQuoteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Status:  http.StatusBadRequest,
 			Error:   "currency query parameter is required",
-			Details: dq.ErrUnsupportedCurrencyPair,
+			Details: err.Error(),
 		})
 		return
 	}
-	q, err := h.QuoteService.GetLatestByCurrency(c.Request.Context(), currency)
+	var maxAge time.Duration
+	if req.MaxAge != "" {
+		d, err := time.ParseDuration(req.MaxAge)
+		if err != nil || d <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: http.StatusBadRequest,
+				Error:  "invalid max_age duration, e.g. 5m",
+			})
+			return
+		}
+		maxAge = d
+	}
+	refresh := h.LatestRefresh
+	if req.Refresh != nil {
+		refresh = *req.Refresh
+	}
+	latest, err := h.QuoteService.GetLatest(c.Request.Context(), req.Currency, maxAge, refresh)
 	if err != nil {
-		if errors.Is(err, dq.ErrQuoteNotFound) {
+		switch {
+		case errors.Is(err, dq.ErrQuoteNotFound):
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Status:  http.StatusNotFound,
 				Error:   "quote not found",
 				Details: err.Error(),
 			})
-			return
-		} else {
+		case errors.Is(err, dq.ErrUnsupportedCurrencyPair):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "currency pair not supported currently",
+				Details: err.Error(),
+			})
+		default:
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
 				Status:  http.StatusInternalServerError,
 				Error:   "internal error",
 				Details: err.Error(),
 			})
+		}
+		return
+	}
+	q := latest.Quote
+	if q != nil {
+		var ok bool
+		if q, ok = h.priceQuote(c, q); !ok {
 			return
 		}
 	}
-	c.JSON(http.StatusOK, SuccessResponse{
-		Status:  http.StatusOK,
-		Message: "latest quote retrieved",
-		Data:    q,
-	})
+	switch {
+	case latest.RefreshID != "":
+		c.JSON(http.StatusAccepted, SuccessResponse{
+			Status:  http.StatusAccepted,
+			Message: "quote is stale or missing, update requested",
+			Data:    RefreshQuoteResponse{UpdateID: latest.RefreshID, Quote: q},
+		})
+	case latest.Stale:
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Status:  http.StatusConflict,
+			Error:   "quote is older than max_age",
+			Details: q,
+		})
+	default:
+		c.JSON(http.StatusOK, SuccessResponse{
+			Status:  http.StatusOK,
+			Message: "latest quote retrieved",
+			Data:    q,
+		})
+	}
 }
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	dq "plata/internal/domain/quote"
+)
+
+// LockQuote locks the current rate of a currency pair
+// @Summary Lock a quote
+// @Description Snapshots the latest rate of the pair, raised by markup_bps, into a lock guaranteed until expires_at.
+// @Description A repeated Idempotency-Key returns the lock created first, or 409 when the terms differ.
+// @Tags quotes
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Idempotency key"
+// @Param request body LockQuoteRequest true "Currency pair, TTL and markup"
+// @Success 201 {object} SuccessResponse{data=dq.Lock}
+// @Failure 400,409,500 {object} ErrorResponse "Error response"
+// @Router /quotes/lock [post]
+func (h *Handler) LockQuote(c *gin.Context) {
+	var req LockQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	l, err := h.LockService.Lock(c.Request.Context(), req.Currency, ttl, req.MarkupBps, c.GetHeader("Idempotency-Key"))
+	if err != nil {
+		switch {
+		case errors.Is(err, dq.ErrInvalidLock):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "invalid request",
+				Details: err.Error(),
+			})
+		case errors.Is(err, dq.ErrUnsupportedCurrencyPair):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "currency pair not supported currently",
+				Details: err.Error(),
+			})
+		case errors.Is(err, dq.ErrIdempotencyConflict):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Status:  http.StatusConflict,
+				Error:   "idempotency key already used for a different lock",
+				Details: err.Error(),
+			})
+		case errors.Is(err, dq.ErrRateUnavailable):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Status:  http.StatusConflict,
+				Error:   "no recent rate to lock, request an update first",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status:  http.StatusInternalServerError,
+				Error:   "failed to lock quote",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Status:  http.StatusCreated,
+		Message: "quote locked",
+		Data:    l,
+	})
+}
+
+// ConsumeQuoteLock uses a locked quote
+// @Summary Consume a quote lock
+// @Description Marks the lock used; succeeds exactly once and only before expires_at.
+// @Tags quotes
+// @Produce json
+// @Param token path string true "Lock token"
+// @Success 200 {object} SuccessResponse{data=dq.Lock}
+// @Failure 400,404,409,410,500 {object} ErrorResponse "Error response"
+// @Router /quotes/lock/{token}/consume [post]
+func (h *Handler) ConsumeQuoteLock(c *gin.Context) {
+	token := c.Param("token")
+	if _, err := uuid.Parse(token); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid token format, must be a UUID",
+			Details: err.Error(),
+		})
+		return
+	}
+	l, err := h.LockService.Consume(c.Request.Context(), token)
+	if err != nil {
+		switch {
+		case errors.Is(err, dq.ErrLockNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Status:  http.StatusNotFound,
+				Error:   "unable to find lock with such token",
+				Details: err.Error(),
+			})
+		case errors.Is(err, dq.ErrLockConsumed):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Status:  http.StatusConflict,
+				Error:   "lock already consumed",
+				Details: err.Error(),
+			})
+		case errors.Is(err, dq.ErrLockExpired):
+			c.JSON(http.StatusGone, ErrorResponse{
+				Status:  http.StatusGone,
+				Error:   "lock expired",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status:  http.StatusInternalServerError,
+				Error:   "internal error",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "quote lock consumed",
+		Data:    l,
+	})
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	dpr "plata/internal/domain/pricing"
+	dq "plata/internal/domain/quote"
+)
+
+// TenantHeader identifies the client a quote is priced for. The service does not
+// authenticate it: the gateway in front must strip it from clients and set it.
+const TenantHeader = "X-Tenant-ID"
+
+// priceQuote returns the quote priced for the tenant and ?amount= of the request, it
+// writes the error response and returns false when pricing fails.
+func (h *Handler) priceQuote(c *gin.Context, q *dq.Quote) (*dq.Quote, bool) {
+	if h.PricingService == nil {
+		return q, true
+	}
+	amount := decimal.Zero
+	if raw := c.Query("amount"); raw != "" {
+		var err error
+		if amount, err = decimal.NewFromString(raw); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "invalid amount",
+				Details: err.Error(),
+			})
+			return nil, false
+		}
+	}
+	priced, err := h.PricingService.Price(c.Request.Context(), q, c.GetHeader(TenantHeader), amount)
+	if err != nil {
+		if errors.Is(err, dpr.ErrInvalidAmount) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "invalid amount",
+				Details: err.Error(),
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "failed to price quote",
+			Details: err.Error(),
+		})
+		return nil, false
+	}
+	return priced, true
+}
+
+// ListPricingRules returns the pricing rules
+// @Summary List pricing rules
+// @Tags admin
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]dpr.Rule}
+// @Failure 500 {object} ErrorResponse "Error response"
+// @Router /admin/pricing-rules [get]
+func (h *Handler) ListPricingRules(c *gin.Context) {
+	rules, err := h.PricingService.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "pricing rules retrieved",
+		Data:    rules,
+	})
+}
+
+// AddPricingRule adds a pricing rule
+// @Summary Add pricing rule
+// @Description Bid and ask are the mid rate minus and plus markup_bps of it and markup_pips pips, from min_amount of the base currency.
+// @Description Omit tenant or pair to apply the rule to all of them; the most specific matching rule wins.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body AddPricingRuleRequest true "Pricing rule"
+// @Success 201 {object} SuccessResponse{data=dpr.Rule}
+// @Failure 400,409,500 {object} ErrorResponse "Error response"
+// @Router /admin/pricing-rules [post]
+func (h *Handler) AddPricingRule(c *gin.Context) {
+	var req AddPricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+	rule, err := req.rule()
+	if err == nil {
+		rule, err = h.PricingService.Add(c.Request.Context(), rule)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, dpr.ErrInvalidRule):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "invalid pricing rule",
+				Details: err.Error(),
+			})
+		case errors.Is(err, dpr.ErrRuleExists):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Status:  http.StatusConflict,
+				Error:   "pricing rule already exists",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status:  http.StatusInternalServerError,
+				Error:   "failed to add pricing rule",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Status:  http.StatusCreated,
+		Message: "pricing rule added",
+		Data:    rule,
+	})
+}
+
+// DeletePricingRule deletes a pricing rule
+// @Summary Delete pricing rule
+// @Tags admin
+// @Produce json
+// @Param id path int true "Pricing rule ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400,404,500 {object} ErrorResponse "Error response"
+// @Router /admin/pricing-rules/{id} [delete]
+func (h *Handler) DeletePricingRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid ID format, must be an integer",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err = h.PricingService.Delete(c.Request.Context(), id); err != nil {
+		if errors.Is(err, dpr.ErrRuleNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Status:  http.StatusNotFound,
+				Error:   "pricing rule not found",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "failed to delete pricing rule",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "pricing rule deleted",
+	})
+}
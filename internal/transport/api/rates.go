@@ -0,0 +1,170 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	dr "plata/internal/domain/rate"
+)
+
+const defaultHistoryWindow = 24 * time.Hour
+
+// GetRateHistory returns the recorded rates of a currency pair over a time range
+// @Summary Rate history
+// @Description Rates of done quotes fetched in [from, to), oldest first. With interval (e.g. 1h) only the last rate of every bucket is returned.
+// @Description Pass next_cursor back as cursor to get the following page.
+// @Tags rates
+// @Produce json
+// @Param currency query string true "Currency pair"
+// @Param from query string false "RFC3339 start, defaults to 24h before to"
+// @Param to query string false "RFC3339 end, defaults to now"
+// @Param interval query string false "Bucket size of at least 1s, e.g. 15m or 1h"
+// @Param limit query int false "Page size, at most 1000"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} SuccessResponse{data=dr.Page}
+// @Failure 400,500 {object} ErrorResponse "Error response"
+// @Router /rates/history [get]
+func (h *Handler) GetRateHistory(c *gin.Context) {
+	var req RateHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+	q, err := req.query(time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+	page, err := h.RateService.History(c.Request.Context(), q)
+	if err != nil {
+		if errors.Is(err, dr.ErrInvalidQuery) || errors.Is(err, dr.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "invalid request",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "rate history retrieved",
+		Data:    page,
+	})
+}
+
+// GetRateCandles returns OHLC candles of a currency pair
+// @Summary Rate candles
+// @Description Open, high, low and close rate with the sample count of every interval bucket starting in [from, to) that holds at least one rate.
+// @Description Buckets are aligned to 2000-01-01T00:00:00Z and from is rounded down to the start of its bucket.
+// @Tags rates
+// @Produce json
+// @Param currency query string true "Currency pair"
+// @Param interval query string true "Bucket size, e.g. 1m, 1h or 24h"
+// @Param from query string false "RFC3339 start, defaults to 24h before to"
+// @Param to query string false "RFC3339 end, defaults to now"
+// @Success 200 {object} SuccessResponse{data=dr.CandleSeries}
+// @Failure 400,500 {object} ErrorResponse "Error response"
+// @Router /rates/candles [get]
+func (h *Handler) GetRateCandles(c *gin.Context) {
+	var req RateCandlesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+	q, err := req.query(time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "invalid request",
+			Details: err.Error(),
+		})
+		return
+	}
+	series, err := h.RateService.Candles(c.Request.Context(), q)
+	if err != nil {
+		if errors.Is(err, dr.ErrInvalidQuery) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "invalid request",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "candles retrieved",
+		Data:    series,
+	})
+}
+
+// GetRateAt returns the last known rate of a currency pair at a point in time
+// @Summary Rate at a point in time
+// @Description The last rate recorded for the pair at or before at
+// @Tags rates
+// @Produce json
+// @Param currency query string true "Currency pair"
+// @Param at query string true "RFC3339 time"
+// @Success 200 {object} SuccessResponse{data=dr.Rate}
+// @Failure 400,404,500 {object} ErrorResponse "Error response"
+// @Router /rates/at [get]
+func (h *Handler) GetRateAt(c *gin.Context) {
+	currency := c.Query("currency")
+	at, err := time.Parse(time.RFC3339, c.Query("at"))
+	if currency == "" || err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: http.StatusBadRequest,
+			Error:  "currency and an RFC3339 at are required",
+		})
+		return
+	}
+	r, err := h.RateService.GetRateAt(c.Request.Context(), currency, at)
+	if err != nil {
+		if errors.Is(err, dr.ErrRateNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Status:  http.StatusNotFound,
+				Error:   "no rate recorded before this time",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "rate found",
+		Data:    r,
+	})
+}
@@ -1,9 +1,149 @@
 package api
 
+import (
+	"fmt"
+	"plata/internal/domain/pricing"
+	dq "plata/internal/domain/quote"
+	"plata/internal/domain/rate"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 type UpdateQuoteRequest struct {
 	Currency string `json:"currency" binding:"required,len=7"`
+	// CallbackURL receives a signed POST once the update is done, failed or expired; its host must be allow-listed.
+	CallbackURL string `json:"callback_url,omitempty"`
 }
 
 type UpdateQuoteResponse struct {
 	UpdateID string `json:"update_id"`
 }
+
+type StatusResponse struct {
+	InstanceID string `json:"instance_id"`
+	IsLeader   bool   `json:"is_leader"`
+	LeaderID   string `json:"leader_id"`
+}
+
+type AddPairRequest struct {
+	Pair      string `json:"pair" binding:"required,len=7"`
+	Enabled   *bool  `json:"enabled"`
+	Precision *int   `json:"precision"`
+	Provider  string `json:"provider"`
+}
+
+type RateHistoryRequest struct {
+	Currency string `form:"currency" binding:"required,len=7"`
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Interval string `form:"interval"`
+	Limit    int    `form:"limit"`
+	Cursor   string `form:"cursor"`
+}
+
+func (r RateHistoryRequest) query(now time.Time) (rate.HistoryQuery, error) {
+	q := rate.HistoryQuery{Pair: r.Currency, Limit: r.Limit}
+	var err error
+	if q.From, q.To, err = parseRange(r.From, r.To, now); err != nil {
+		return q, err
+	}
+	if r.Interval != "" {
+		if q.Interval, err = time.ParseDuration(r.Interval); err != nil {
+			return q, fmt.Errorf("interval: %w", err)
+		}
+	}
+	if r.Cursor != "" {
+		if q.After, err = rate.DecodeCursor(r.Cursor); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
+
+type RateCandlesRequest struct {
+	Currency string `form:"currency" binding:"required,len=7"`
+	Interval string `form:"interval" binding:"required"`
+	From     string `form:"from"`
+	To       string `form:"to"`
+}
+
+func (r RateCandlesRequest) query(now time.Time) (rate.CandleQuery, error) {
+	q := rate.CandleQuery{Pair: r.Currency}
+	var err error
+	if q.From, q.To, err = parseRange(r.From, r.To, now); err != nil {
+		return q, err
+	}
+	if q.Interval, err = time.ParseDuration(r.Interval); err != nil {
+		return q, fmt.Errorf("interval: %w", err)
+	}
+	return q, nil
+}
+
+// parseRange parses an RFC3339 time range, to defaults to now and from to a day before to.
+func parseRange(rawFrom, rawTo string, now time.Time) (from, to time.Time, err error) {
+	to = now
+	if rawTo != "" {
+		if to, err = time.Parse(time.RFC3339, rawTo); err != nil {
+			return from, to, fmt.Errorf("to: %w", err)
+		}
+	}
+	from = to.Add(-defaultHistoryWindow)
+	if rawFrom != "" {
+		if from, err = time.Parse(time.RFC3339, rawFrom); err != nil {
+			return from, to, fmt.Errorf("from: %w", err)
+		}
+	}
+	return from.UTC(), to.UTC(), nil
+}
+
+type ConvertRequest struct {
+	From     string `form:"from" binding:"required,len=3"`
+	To       string `form:"to" binding:"required,len=3"`
+	Amount   string `form:"amount" binding:"required"`
+	At       string `form:"at"`
+	Rounding string `form:"rounding"`
+}
+
+type LockQuoteRequest struct {
+	Currency string `json:"currency" binding:"required,len=7"`
+	// TTLSeconds is how long the rate is guaranteed, the server default when omitted.
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
+	MarkupBps  int `json:"markup_bps,omitempty"`
+}
+
+type AddPricingRuleRequest struct {
+	Tenant     string `json:"tenant"`
+	Pair       string `json:"pair"`
+	MinAmount  string `json:"min_amount" example:"10000"`
+	MarkupBps  int    `json:"markup_bps"`
+	MarkupPips string `json:"markup_pips" example:"1.5"`
+}
+
+func (r AddPricingRuleRequest) rule() (*pricing.Rule, error) {
+	rule := &pricing.Rule{Tenant: r.Tenant, Pair: r.Pair, MarkupBps: r.MarkupBps}
+	var err error
+	if r.MinAmount != "" {
+		if rule.MinAmount, err = decimal.NewFromString(r.MinAmount); err != nil {
+			return nil, fmt.Errorf("%w: min_amount: %v", pricing.ErrInvalidRule, err)
+		}
+	}
+	if r.MarkupPips != "" {
+		if rule.MarkupPips, err = decimal.NewFromString(r.MarkupPips); err != nil {
+			return nil, fmt.Errorf("%w: markup_pips: %v", pricing.ErrInvalidRule, err)
+		}
+	}
+	return rule, nil
+}
+
+type LatestQuoteRequest struct {
+	Currency string `form:"currency" binding:"required"`
+	MaxAge   string `form:"max_age"`
+	Refresh  *bool  `form:"refresh"`
+}
+
+type RefreshQuoteResponse struct {
+	UpdateID string `json:"update_id"`
+	// Quote is the stale quote, omitted when the pair has none yet.
+	Quote *dq.Quote `json:"quote,omitempty"`
+}
@@ -3,23 +3,32 @@ package api
 import (
 	"context"
 	"errors"
+	"expvar"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
 	"plata/internal/common/log"
+	"plata/internal/config"
 
 	"net/http"
 )
 
 type Service struct {
-	srv *http.Server
-	log log.Logger
+	srv  *http.Server
+	addr string
+	log  log.Logger
 }
 
-func NewServer(log log.Logger) *Service {
+const defaultAddr = ":8080"
+
+func NewServer(cfg config.ServerConfig, log log.Logger) *Service {
+	if cfg.Addr == "" {
+		cfg.Addr = defaultAddr
+	}
 	return &Service{
-		log: log,
+		addr: cfg.Addr,
+		log:  log,
 	}
 }
 
@@ -35,17 +44,58 @@ func (s *Service) InitServer(handler *Handler) error {
 
 		// 3. get last quote by pair (GET /api/v1/quotes/latest/:pair)
 		api.GET("/latest", handler.GetLatestQuote)
+
+		// delivery log of the callbacks sent for a quote (GET /api/v1/quotes/:id/callbacks)
+		api.GET("/:id/callbacks", handler.ListCallbacks)
+
+		// firm, time-limited rates (POST /api/v1/quotes/lock, /api/v1/quotes/lock/:token/consume)
+		api.POST("/lock", handler.LockQuote)
+		api.POST("/lock/:token/consume", handler.ConsumeQuoteLock)
+
+		// 4. live done quotes as Server-Sent Events (GET /api/v1/quotes/stream)
+		api.GET("/stream", handler.StreamQuotes)
+	}
+	admin := r.Group("/api/v1/admin")
+	{
+		// currency pair catalogue (GET/POST /api/v1/admin/pairs)
+		admin.GET("/pairs", handler.ListPairs)
+		admin.POST("/pairs", handler.AddPair)
+		admin.POST("/pairs/:base/:target/enable", handler.EnablePair)
+		admin.POST("/pairs/:base/:target/disable", handler.DisablePair)
+
+		// bid/ask markup rules (GET/POST /api/v1/admin/pricing-rules)
+		admin.GET("/pricing-rules", handler.ListPricingRules)
+		admin.POST("/pricing-rules", handler.AddPricingRule)
+		admin.DELETE("/pricing-rules/:id", handler.DeletePricingRule)
+	}
+	rates := r.Group("/api/v1/rates")
+	{
+		// rate history over a time range (GET /api/v1/rates/history)
+		rates.GET("/history", handler.GetRateHistory)
+
+		// OHLC candles per interval bucket (GET /api/v1/rates/candles)
+		rates.GET("/candles", handler.GetRateCandles)
+
+		// last known rate at a point in time (GET /api/v1/rates/at)
+		rates.GET("/at", handler.GetRateAt)
 	}
+	// currency conversion with latest or point-in-time rates (GET /api/v1/convert)
+	r.GET("/api/v1/convert", handler.Convert)
+	// instance and leader status (GET /api/v1/status)
+	r.GET("/api/v1/status", handler.GetStatus)
 	// Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Updater and runtime metrics
+	r.GET("/debug/vars", gin.WrapH(expvar.Handler()))
 	// Healthcheck
 	r.GET("/ping", func(c *gin.Context) {
 		c.String(http.StatusOK, "pong")
 	})
 	s.srv = &http.Server{
-		Addr:    ":8080",
+		Addr:    s.addr,
 		Handler: r,
 	}
+	s.srv.RegisterOnShutdown(handler.CloseStreams)
 	if err := s.run(); err != nil {
 		return fmt.Errorf("failed to start server: %w", err)
 	}
@@ -53,7 +103,7 @@ func (s *Service) InitServer(handler *Handler) error {
 }
 
 func (s *Service) run() error {
-	s.log.Info("Starting server on :8080")
+	s.log.Infof("Starting server on %s", s.addr)
 	go func() {
 		if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			s.log.Errorf("HTTP server error: %v", err)
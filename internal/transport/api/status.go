@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetStatus reports the instance identity and the current updater leader
+// @Summary Instance status
+// @Description Returns this instance ID and the ID of the instance currently running leader-only jobs
+// @Tags status
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=StatusResponse}
+// @Failure 500 {object} ErrorResponse "Error response"
+// @Router /status [get]
+func (h *Handler) GetStatus(c *gin.Context) {
+	leaderID, err := h.Leader.Leader(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Error:   "failed to resolve leader",
+			Details: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "status retrieved",
+		Data: StatusResponse{
+			InstanceID: h.Leader.ID(),
+			IsLeader:   h.Leader.IsLeader(),
+			LeaderID:   leaderID,
+		},
+	})
+}
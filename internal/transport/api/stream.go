@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"plata/internal/domain/pair"
+)
+
+// StreamQuotes streams done quotes as Server-Sent Events
+// @Summary Stream quote updates
+// @Description Emits a `quote` event (id = quote ID) whenever a done quote is written for one of the pairs.
+// @Description Send Last-Event-ID to replay the quotes missed since that event. Slow clients are disconnected and should resume the same way.
+// @Tags quotes
+// @Produce text/event-stream
+// @Param currency query string true "Comma separated currency pairs, e.g. EUR/USD,EUR/MXN"
+// @Param Last-Event-ID header string false "ID of the last quote received"
+// @Success 200 {string} string "quote events, data is the JSON quote"
+// @Failure 400,500,503 {object} ErrorResponse "Error response"
+// @Router /quotes/stream [get]
+func (h *Handler) StreamQuotes(c *gin.Context) {
+	var currencies []string
+	for _, cur := range strings.Split(c.Query("currency"), ",") {
+		cur = strings.TrimSpace(cur)
+		if cur == "" {
+			continue
+		}
+		if err := pair.Validate(&pair.Pair{Pair: cur}); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "invalid currency pair",
+				Details: cur,
+			})
+			return
+		}
+		currencies = append(currencies, cur)
+	}
+	if len(currencies) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: http.StatusBadRequest,
+			Error:  "currency query parameter is required",
+		})
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID != "" {
+		if _, err := uuid.Parse(lastEventID); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "invalid Last-Event-ID, must be a quote ID",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	updates, err := h.QuoteService.Stream(ctx, currencies, lastEventID, h.StreamBuffer)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Status:  http.StatusServiceUnavailable,
+			Error:   "failed to open quote stream",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.StreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case q, ok := <-updates:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(q)
+			if err != nil {
+				return
+			}
+			if _, err = fmt.Fprintf(c.Writer, "id: %s\nevent: quote\ndata: %s\n\n", q.ID, data); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		case <-h.streams.Done():
+			return
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS provider VARCHAR(64);
@@ -0,0 +1,3 @@
+ALTER TABLE quotes ALTER COLUMN provider TYPE VARCHAR(255);
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS sources INT NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS spread NUMERIC(18, 6) NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS derived BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS legs TEXT[];
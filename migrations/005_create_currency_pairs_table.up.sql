@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS currency_pairs (
+    pair VARCHAR(20) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    precision SMALLINT NOT NULL DEFAULT 6,
+    provider VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO currency_pairs (pair) VALUES
+    ('EUR/USD'),
+    ('EUR/MXN'),
+    ('EUR/RUB')
+ON CONFLICT (pair) DO NOTHING;
@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS scale SMALLINT NOT NULL DEFAULT 6;
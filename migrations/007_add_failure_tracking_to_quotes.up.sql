@@ -0,0 +1,6 @@
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+UPDATE quotes SET created_at = updated_at;
+
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS error_code VARCHAR(64);
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS error_message TEXT;
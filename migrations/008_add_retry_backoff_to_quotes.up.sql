@@ -0,0 +1,7 @@
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS last_attempt_at TIMESTAMP;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+CREATE INDEX IF NOT EXISTS quotes_pending_next_attempt_idx
+    ON quotes (next_attempt_at)
+    WHERE status = 'in_progress';
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS quote_update_runs (
+    id UUID PRIMARY KEY,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    updated INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    skipped INT NOT NULL DEFAULT 0,
+    error TEXT,
+    groups JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS quote_update_runs_started_at_idx ON quote_update_runs (started_at DESC);
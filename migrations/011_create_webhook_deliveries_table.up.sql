@@ -0,0 +1,34 @@
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS callback_url TEXT;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY,
+    quote_id UUID NOT NULL REFERENCES quotes (id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    lease_owner VARCHAR(128),
+    lease_expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_quote_id_idx ON webhook_deliveries (quote_id);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    delivery_id UUID NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    status_code INT,
+    error TEXT,
+    duration_ms BIGINT NOT NULL,
+    attempted_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_id_idx ON webhook_delivery_attempts (delivery_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS quote_events (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    type VARCHAR(32) NOT NULL,
+    quote_id UUID NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    payload TEXT NOT NULL,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS quote_events_pending_idx
+    ON quote_events (seq)
+    WHERE published_at IS NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS quote_rates (
+    id BIGSERIAL PRIMARY KEY,
+    pair VARCHAR(20) NOT NULL,
+    rate NUMERIC(18, 6) NOT NULL,
+    scale SMALLINT NOT NULL DEFAULT 6,
+    provider VARCHAR(255),
+    fetched_at TIMESTAMP NOT NULL,
+    quote_id UUID REFERENCES quotes (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS quote_rates_pair_fetched_at_idx ON quote_rates (pair, fetched_at, id);
+
+INSERT INTO quote_rates (pair, rate, scale, provider, fetched_at, quote_id)
+SELECT currency, amount, scale, provider, updated_at, id
+FROM quotes
+WHERE status = 'done' AND amount IS NOT NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS rate_candles (
+    pair VARCHAR(20) NOT NULL,
+    interval_seconds INTEGER NOT NULL,
+    bucket TIMESTAMP NOT NULL,
+    open NUMERIC(18, 6) NOT NULL,
+    high NUMERIC(18, 6) NOT NULL,
+    low NUMERIC(18, 6) NOT NULL,
+    close NUMERIC(18, 6) NOT NULL,
+    scale SMALLINT NOT NULL DEFAULT 6,
+    samples INTEGER NOT NULL,
+    PRIMARY KEY (pair, interval_seconds, bucket)
+);
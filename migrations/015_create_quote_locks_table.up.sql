@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS quote_locks (
+    token UUID PRIMARY KEY,
+    quote_id UUID REFERENCES quotes (id) ON DELETE SET NULL,
+    currency VARCHAR(20) NOT NULL,
+    mid_rate NUMERIC(18, 6) NOT NULL,
+    rate NUMERIC(18, 6) NOT NULL,
+    scale SMALLINT NOT NULL DEFAULT 6,
+    markup_bps INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP,
+    idempotency_key VARCHAR(255) UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS quote_locks_quote_id_idx ON quote_locks (quote_id);
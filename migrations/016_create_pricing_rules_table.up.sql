@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id BIGSERIAL PRIMARY KEY,
+    tenant VARCHAR(64),
+    pair VARCHAR(20),
+    min_amount NUMERIC(20, 2) NOT NULL DEFAULT 0,
+    markup_bps INT NOT NULL DEFAULT 0,
+    markup_pips NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS pricing_rules_scope_idx
+    ON pricing_rules (COALESCE(tenant, ''), COALESCE(pair, ''), min_amount);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS quotes_currency_status_updated_at_idx ON quotes (currency, status, updated_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS origin VARCHAR(16) NOT NULL DEFAULT 'request';
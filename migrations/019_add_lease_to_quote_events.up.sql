@@ -0,0 +1,2 @@
+ALTER TABLE quote_events ADD COLUMN IF NOT EXISTS lease_owner VARCHAR(128);
+ALTER TABLE quote_events ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMP;
@@ -0,0 +1,3 @@
+ALTER TABLE quote_update_runs ADD COLUMN IF NOT EXISTS origin VARCHAR(16) NOT NULL DEFAULT 'request';
+
+CREATE INDEX IF NOT EXISTS quotes_pending_system_idx ON quotes (currency) WHERE status = 'in_progress' AND origin = 'system';
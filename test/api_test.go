@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"plata/internal/app/hub"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"plata/internal/domain/quote"
+	"plata/internal/domain/webhook"
+	qs "plata/internal/services/quote"
+	ws "plata/internal/services/webhook"
+	"plata/internal/transport/api"
+)
+
+func TestStreamQuotes_RejectsMalformedLastEventID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := new(mockRepo)
+	handler := api.NewHandler(
+		qs.New(repo, nil, nil, hub.New(log.NewZapLogger()), nil, log.NewZapLogger()),
+		nil, nil, nil, nil, nil, nil, nil, config.ServerConfig{},
+	)
+	router := gin.New()
+	router.GET("/quotes/stream", handler.StreamQuotes)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/quotes/stream?currency=EUR/USD", nil)
+	req.Header.Set("Last-Event-ID", "not-a-uuid")
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	repo.AssertNotCalled(t, "ListDoneAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListCallbacks_HidesCallbackURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := new(mockRepo)
+	deliveries := new(mockDeliveryRepo)
+	handler := api.NewHandler(
+		qs.New(repo, nil, nil, nil, nil, log.NewZapLogger()), nil,
+		ws.New(deliveries, []string{"hooks.example.com"}, log.NewZapLogger()),
+		nil, nil, nil, nil, nil, config.ServerConfig{},
+	)
+	router := gin.New()
+	router.GET("/quotes/:id/callbacks", handler.ListCallbacks)
+
+	id := uuid.NewString()
+	const callbackURL = "https://hooks.example.com/plata/s3cr3t-token?sig=abc"
+	repo.On("GetByID", mock.Anything, id).Return(&quote.Quote{ID: id, Status: quote.StatusDone, CallbackURL: callbackURL}, nil)
+	deliveries.On("ListByQuote", mock.Anything, id).Return([]*webhook.Delivery{{
+		ID:        uuid.NewString(),
+		QuoteID:   id,
+		URL:       callbackURL,
+		Event:     "quote.done",
+		Status:    webhook.StatusDelivered,
+		Attempts:  1,
+		CreatedAt: time.Now(),
+		Log:       []*webhook.Attempt{{Attempt: 1, StatusCode: http.StatusOK}},
+	}}, nil)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/quotes/"+id+"/callbacks", nil)
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"delivered"`)
+	assert.NotContains(t, rec.Body.String(), "hooks.example.com/plata")
+	assert.NotContains(t, rec.Body.String(), "s3cr3t-token")
+}
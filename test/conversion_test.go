@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"plata/internal/domain/conversion"
+	"plata/internal/domain/rate"
+	cs "plata/internal/services/conversion"
+)
+
+func newConverter(rates *mockRateRepo) *cs.Service {
+	return cs.New(config.ConvertConfig{
+		MaxStaleness: time.Hour,
+		Pivots:       []string{"EUR", "USD"},
+	}, rates, log.NewZapLogger())
+}
+
+func storedRate(pair, value, quoteID string, fetchedAt time.Time) *rate.Rate {
+	return &rate.Rate{Pair: pair, Rate: decimal.RequireFromString(value), Scale: 6, QuoteID: quoteID, FetchedAt: fetchedAt}
+}
+
+func TestConvert_UsesDirectPair(t *testing.T) {
+	rates := new(mockRateRepo)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	rates.On("GetAt", ctx, "EUR/MXN", mock.AnythingOfType("time.Time")).
+		Return(storedRate("EUR/MXN", "18.7345", "q1", now.Add(-time.Minute)), nil)
+
+	c, err := newConverter(rates).Convert(ctx, conversion.Request{From: "EUR", To: "MXN", Amount: decimal.RequireFromString("125.40")})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2349.31", c.Result.StringFixed(2))
+	assert.Equal(t, "q1", c.QuoteID)
+	assert.Len(t, c.Legs, 1)
+}
+
+func TestConvert_InvertsReversePair(t *testing.T) {
+	rates := new(mockRateRepo)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	rates.On("GetAt", ctx, "MXN/EUR", mock.Anything).Return(nil, rate.ErrRateNotFound)
+	rates.On("GetAt", ctx, "EUR/MXN", mock.Anything).Return(storedRate("EUR/MXN", "20", "q1", now), nil)
+
+	c, err := newConverter(rates).Convert(ctx, conversion.Request{From: "MXN", To: "EUR", Amount: decimal.RequireFromString("100")})
+
+	assert.NoError(t, err)
+	assert.True(t, c.Legs[0].Inverted)
+	assert.Equal(t, "0.0500000000", c.Rate.StringFixed(conversion.RateScale))
+	assert.Equal(t, "5.00", c.Result.StringFixed(2))
+	assert.Equal(t, "q1", c.QuoteID)
+}
+
+func TestConvert_TriangulatesThroughPivot(t *testing.T) {
+	rates := new(mockRateRepo)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for _, name := range []string{"USD/MXN", "MXN/USD", "USD/EUR"} {
+		rates.On("GetAt", ctx, name, mock.Anything).Return(nil, rate.ErrRateNotFound)
+	}
+	rates.On("GetAt", ctx, "EUR/USD", mock.Anything).Return(storedRate("EUR/USD", "1.25", "q1", now.Add(-10*time.Minute)), nil)
+	rates.On("GetAt", ctx, "EUR/MXN", mock.Anything).Return(storedRate("EUR/MXN", "20", "q2", now), nil)
+
+	c, err := newConverter(rates).Convert(ctx, conversion.Request{From: "USD", To: "MXN", Amount: decimal.RequireFromString("10")})
+
+	assert.NoError(t, err)
+	assert.Len(t, c.Legs, 2)
+	assert.Equal(t, "160.00", c.Result.StringFixed(2))
+	assert.Empty(t, c.QuoteID)
+	assert.True(t, c.RateTime.Equal(now.Add(-10*time.Minute)))
+}
+
+func TestConvert_RejectsStaleRate(t *testing.T) {
+	rates := new(mockRateRepo)
+	ctx := context.Background()
+	at := time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)
+	rates.On("GetAt", ctx, "EUR/USD", at).Return(storedRate("EUR/USD", "1.08", "q1", at.Add(-2*time.Hour)), nil)
+
+	_, err := newConverter(rates).Convert(ctx, conversion.Request{From: "EUR", To: "USD", Amount: decimal.NewFromInt(1), At: &at})
+
+	assert.ErrorIs(t, err, conversion.ErrStaleRate)
+}
+
+func TestConvert_SkipsPivotWithStaleLegs(t *testing.T) {
+	rates := new(mockRateRepo)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for _, name := range []string{"GBP/JPY", "JPY/GBP", "JPY/EUR", "JPY/USD"} {
+		rates.On("GetAt", ctx, name, mock.Anything).Return(nil, rate.ErrRateNotFound)
+	}
+	rates.On("GetAt", ctx, "GBP/EUR", mock.Anything).Return(storedRate("GBP/EUR", "1.2", "q1", now.Add(-3*time.Hour)), nil)
+	rates.On("GetAt", ctx, "EUR/JPY", mock.Anything).Return(storedRate("EUR/JPY", "160", "q2", now), nil)
+	rates.On("GetAt", ctx, "GBP/USD", mock.Anything).Return(storedRate("GBP/USD", "1.25", "q3", now), nil)
+	rates.On("GetAt", ctx, "USD/JPY", mock.Anything).Return(storedRate("USD/JPY", "150", "q4", now), nil)
+
+	c, err := newConverter(rates).Convert(ctx, conversion.Request{From: "GBP", To: "JPY", Amount: decimal.NewFromInt(10)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "GBP/USD", c.Legs[0].Pair)
+	assert.Equal(t, "1875", c.Result.String())
+}
+
+func TestConvert_RejectsWhenEveryPathIsStale(t *testing.T) {
+	rates := new(mockRateRepo)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for _, name := range []string{"GBP/JPY", "JPY/GBP", "JPY/EUR", "GBP/USD", "USD/GBP"} {
+		rates.On("GetAt", ctx, name, mock.Anything).Return(nil, rate.ErrRateNotFound)
+	}
+	rates.On("GetAt", ctx, "GBP/EUR", mock.Anything).Return(storedRate("GBP/EUR", "1.2", "q1", now.Add(-3*time.Hour)), nil)
+	rates.On("GetAt", ctx, "EUR/JPY", mock.Anything).Return(storedRate("EUR/JPY", "160", "q2", now), nil)
+
+	_, err := newConverter(rates).Convert(ctx, conversion.Request{From: "GBP", To: "JPY", Amount: decimal.NewFromInt(1)})
+
+	assert.ErrorIs(t, err, conversion.ErrStaleRate)
+}
+
+func TestConvert_NoRate(t *testing.T) {
+	rates := new(mockRateRepo)
+	ctx := context.Background()
+	rates.On("GetAt", ctx, mock.Anything, mock.Anything).Return(nil, rate.ErrRateNotFound)
+
+	_, err := newConverter(rates).Convert(ctx, conversion.Request{From: "GBP", To: "JPY", Amount: decimal.NewFromInt(1)})
+
+	assert.ErrorIs(t, err, conversion.ErrRateUnavailable)
+}
+
+func TestConvert_RoundsToMinorUnitsWithMode(t *testing.T) {
+	rates := new(mockRateRepo)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	rates.On("GetAt", ctx, "EUR/JPY", mock.Anything).Return(storedRate("EUR/JPY", "162.5", "q1", now), nil)
+	amount := decimal.RequireFromString("1.01")
+
+	for mode, want := range map[conversion.RoundingMode]string{
+		conversion.RoundHalfEven: "164",
+		conversion.RoundUp:       "165",
+		conversion.RoundDown:     "164",
+	} {
+		c, err := newConverter(rates).Convert(ctx, conversion.Request{From: "EUR", To: "JPY", Amount: amount, Rounding: mode})
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, c.Result.String(), string(mode))
+		data, _ := json.Marshal(c)
+		assert.Contains(t, string(data), `"result":"`+want+`"`)
+	}
+}
+
+func TestConvert_RejectsInvalidInput(t *testing.T) {
+	service := newConverter(new(mockRateRepo))
+	ctx := context.Background()
+
+	_, err := service.Convert(ctx, conversion.Request{From: "eur", To: "USD", Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, conversion.ErrInvalidCurrency)
+
+	_, err = service.Convert(ctx, conversion.Request{From: "EUR", To: "USD", Amount: decimal.NewFromInt(-1)})
+	assert.ErrorIs(t, err, conversion.ErrInvalidAmount)
+
+	_, err = conversion.ParseRoundingMode("sideways")
+	assert.ErrorIs(t, err, conversion.ErrUnknownRounding)
+}
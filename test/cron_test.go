@@ -0,0 +1,561 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"plata/internal/clients/exchange"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	cU "plata/internal/app/cron"
+	"plata/internal/domain/pair"
+	"plata/internal/domain/quote"
+)
+
+func onClaim(repo *mockRepo, ctx context.Context) *mock.Call {
+	return repo.On("ClaimDueQuotes", ctx,
+		mock.AnythingOfType("string"),
+		mock.AnythingOfType("time.Time"),
+		mock.AnythingOfType("time.Duration"),
+		mock.AnythingOfType("int"),
+		mock.AnythingOfType("string"),
+	)
+}
+
+func TestUpdateQuotes_TriangulatesRejectedBase(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{
+		Triangulation: config.TriangulationConfig{Pivot: "EUR"},
+	}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	q := &quote.Quote{ID: "q1", Currency: "USD/MXN", Scale: 6, Status: quote.StatusInProgress}
+
+	onClaim(repo, ctx).Return([]*quote.Quote{q}, nil)
+	fetcher.On("FetchRates", ctx, "USD", []string{"MXN"}).
+		Return(nil, fmt.Errorf("%w: USD", exchange.ErrUnsupportedBase))
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD", "MXN"}).
+		Return(&exchange.Rates{Provider: "ecb", Values: map[string]decimal.Decimal{
+			"USD": decimal.RequireFromString("1.25"),
+			"MXN": decimal.RequireFromString("22.5"),
+		}, Spread: map[string]decimal.Decimal{
+			"USD": decimal.RequireFromString("0.0125"),
+			"MXN": decimal.RequireFromString("0.045"),
+		}}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, quote.StatusDone, q.Status)
+	assert.Equal(t, "18.000000", q.Amount.StringFixed(q.Scale))
+	// 18 * (0.045/22.5 + 0.0125/1.25)
+	assert.Equal(t, "0.216000", q.Spread.StringFixed(q.Scale))
+	assert.True(t, q.Derived)
+	assert.Equal(t, []string{"EUR/MXN", "EUR/USD"}, q.Legs)
+	repo.AssertExpectations(t)
+	fetcher.AssertExpectations(t)
+}
+
+func TestUpdateQuotes_TriangulatesConfiguredBase(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{
+		Triangulation: config.TriangulationConfig{Pivot: "EUR", UnsupportedBases: []string{"USD"}},
+	}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	q := &quote.Quote{ID: "q1", Currency: "USD/EUR", Scale: 4, Status: quote.StatusInProgress}
+
+	onClaim(repo, ctx).Return([]*quote.Quote{q}, nil)
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD"}).
+		Return(&exchange.Rates{Provider: "ecb", Values: map[string]decimal.Decimal{"USD": decimal.RequireFromString("1.25")}}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, "0.8000", q.Amount.StringFixed(q.Scale))
+	assert.Equal(t, []string{"EUR/USD"}, q.Legs)
+	fetcher.AssertNotCalled(t, "FetchRates", ctx, "USD", mock.Anything)
+}
+
+func TestUpdateQuotes_MarksFailedAfterMaxAttempts(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{MaxAttempts: 3}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	q := &quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, Attempts: 2, CreatedAt: time.Now()}
+
+	onClaim(repo, ctx).Return([]*quote.Quote{q}, nil)
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD"}).Return(nil, errors.New("quota exceeded"))
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, quote.StatusFailed, q.Status)
+	assert.Equal(t, 3, q.Attempts)
+	assert.Equal(t, quote.ErrorCodeProvider, q.ErrorCode)
+	assert.Contains(t, q.ErrorMessage, "quota exceeded")
+}
+
+func TestUpdateQuotes_KeepsRetryingBelowMaxAttempts(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{MaxAttempts: 3}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	q := &quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, CreatedAt: time.Now()}
+
+	onClaim(repo, ctx).Return([]*quote.Quote{q}, nil)
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD"}).
+		Return(&exchange.Rates{Provider: "p", Values: map[string]decimal.Decimal{}}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, quote.StatusInProgress, q.Status)
+	assert.Equal(t, 1, q.Attempts)
+	repo.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestUpdateQuotes_ExpiresOldQuotes(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{MaxAge: time.Minute}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	q := &quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, CreatedAt: time.Now().Add(-time.Hour)}
+
+	onClaim(repo, ctx).Return([]*quote.Quote{q}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, quote.StatusExpired, q.Status)
+	assert.Equal(t, quote.ErrorCodeExpired, q.ErrorCode)
+	fetcher.AssertNotCalled(t, "FetchRates", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateQuotes_SchedulesRetryWithBackoff(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{
+		MaxAttempts: 10,
+		Retry:       config.RetryConfig{BaseDelay: time.Minute, MaxDelay: time.Hour},
+	}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	q := &quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, Attempts: 3, CreatedAt: time.Now()}
+
+	onClaim(repo, ctx).Return([]*quote.Quote{q}, nil)
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD"}).Return(nil, errors.New("timeout"))
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	before := time.Now()
+	updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, quote.StatusInProgress, q.Status)
+	assert.Equal(t, 4, q.Attempts)
+	assert.Equal(t, "timeout", q.LastError)
+	if assert.NotNil(t, q.NextAttemptAt) {
+		assert.WithinDuration(t, before.Add(8*time.Minute), *q.NextAttemptAt, time.Second)
+	}
+}
+
+func TestUpdateQuotes_BackoffIsCappedAndJittered(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{
+		Retry: config.RetryConfig{BaseDelay: time.Minute, MaxDelay: 5 * time.Minute, Jitter: 0.2},
+	}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	q := &quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, Attempts: 20, CreatedAt: time.Now()}
+
+	onClaim(repo, ctx).Return([]*quote.Quote{q}, nil)
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD"}).Return(nil, errors.New("timeout"))
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	before := time.Now()
+	updater.UpdateQuotes(ctx)
+
+	if assert.NotNil(t, q.NextAttemptAt) {
+		delay := q.NextAttemptAt.Sub(before)
+		assert.GreaterOrEqual(t, delay, 4*time.Minute)
+		assert.LessOrEqual(t, delay, 6*time.Minute+time.Second)
+	}
+}
+
+func TestUpdateQuotes_IsolatesFailingGroups(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{MaxAttempts: 5}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	now := time.Now()
+	gbp := &quote.Quote{ID: "q1", Currency: "GBP/USD", Status: quote.StatusInProgress, CreatedAt: now}
+	usd := &quote.Quote{ID: "q2", Currency: "EUR/USD", Status: quote.StatusInProgress, CreatedAt: now}
+	xxx := &quote.Quote{ID: "q3", Currency: "EUR/XXX", Status: quote.StatusInProgress, CreatedAt: now}
+	mxn := &quote.Quote{ID: "q4", Currency: "EUR/MXN", Status: quote.StatusInProgress, CreatedAt: now}
+
+	onClaim(repo, ctx).
+		Return([]*quote.Quote{gbp, usd, xxx, mxn}, nil)
+	fetcher.On("FetchRates", ctx, "GBP", []string{"USD"}).Return(nil, errors.New("provider down"))
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD", "XXX", "MXN"}).
+		Return(&exchange.Rates{Provider: "p", Values: map[string]decimal.Decimal{
+			"USD": decimal.RequireFromString("1.08"),
+			"MXN": decimal.RequireFromString("18.5"),
+		}}, nil)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(q *quote.Quote) bool { return q.ID != "q2" })).Return(nil)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(q *quote.Quote) bool { return q.ID == "q2" })).Return(errors.New("db down"))
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	run := updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, quote.StatusInProgress, gbp.Status)
+	assert.Equal(t, quote.StatusInProgress, xxx.Status)
+	assert.Equal(t, quote.StatusDone, mxn.Status)
+	assert.Equal(t, 1, run.Updated)
+	assert.Equal(t, 3, run.Failed)
+	assert.Equal(t, 1, run.Groups["GBP"].Failed)
+	assert.Equal(t, "provider down", run.Groups["GBP"].Error)
+	assert.Equal(t, 1, run.Groups["EUR"].Updated)
+	assert.Equal(t, 2, run.Groups["EUR"].Failed)
+	repo.AssertCalled(t, "SaveRun", ctx, run)
+}
+
+func TestUpdateQuotes_ClaimsInBatches(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{InstanceID: "replica-1", BatchSize: 2, LeaseTTL: time.Minute},
+		repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	now := time.Now()
+	first := []*quote.Quote{
+		{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, CreatedAt: now},
+		{ID: "q2", Currency: "EUR/MXN", Status: quote.StatusInProgress, CreatedAt: now},
+	}
+	second := []*quote.Quote{
+		{ID: "q3", Currency: "EUR/USD", Status: quote.StatusInProgress, CreatedAt: now},
+	}
+	rates := &exchange.Rates{Provider: "p", Values: map[string]decimal.Decimal{
+		"USD": decimal.RequireFromString("1.08"),
+		"MXN": decimal.RequireFromString("18.5"),
+	}}
+
+	repo.On("ClaimDueQuotes", ctx, "replica-1", mock.AnythingOfType("time.Time"), time.Minute, 2, "").Return(first, nil).Once()
+	repo.On("ClaimDueQuotes", ctx, "replica-1", mock.AnythingOfType("time.Time"), time.Minute, 2, "").Return(second, nil).Once()
+	fetcher.On("FetchRates", ctx, "EUR", mock.Anything).Return(rates, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	run := updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, 3, run.Updated)
+	repo.AssertNumberOfCalls(t, "ClaimDueQuotes", 2)
+}
+
+func TestUpdateQuotes_LeaseLostCountsAsFailure(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	q := &quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, CreatedAt: time.Now()}
+
+	onClaim(repo, ctx).Return([]*quote.Quote{q}, nil)
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD"}).
+		Return(&exchange.Rates{Provider: "p", Values: map[string]decimal.Decimal{"USD": decimal.RequireFromString("1.08")}}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(quote.ErrLeaseLost)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	run := updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, 0, run.Updated)
+	assert.Equal(t, 1, run.Failed)
+}
+
+func TestUpdateQuotes_DoesNotPublishUnsavedQuotes(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{MaxAge: time.Minute}, repo, nil, fetcher, log.NewZapLogger())
+	var published []*quote.Quote
+	updater.OnUpdate(func(q *quote.Quote) { published = append(published, q) })
+
+	ctx := context.Background()
+	fresh := &quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, CreatedAt: time.Now()}
+	stale := &quote.Quote{ID: "q2", Currency: "EUR/MXN", Status: quote.StatusInProgress, CreatedAt: time.Now().Add(-time.Hour)}
+
+	onClaim(repo, ctx).Return([]*quote.Quote{fresh, stale}, nil)
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD"}).
+		Return(&exchange.Rates{Provider: "p", Values: map[string]decimal.Decimal{"USD": decimal.RequireFromString("1.08")}}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(errors.New("db down"))
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	run := updater.UpdateQuotes(ctx)
+
+	assert.Empty(t, published)
+	assert.Equal(t, quote.StatusInProgress, fresh.Status)
+	assert.Equal(t, 0, run.Updated)
+	assert.Equal(t, 2, run.Failed)
+	assert.Equal(t, 0, run.Skipped)
+}
+
+func TestUpdateQuotes_StoresFetchedRatesAfterCancellation(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, CreatedAt: time.Now()}
+
+	onClaim(repo, ctx).Return([]*quote.Quote{q}, nil).Once()
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD"}).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(&exchange.Rates{Provider: "p", Values: map[string]decimal.Decimal{"USD": decimal.RequireFromString("1.08")}}, nil)
+	repo.On("Update", mock.MatchedBy(func(c context.Context) bool { return c.Err() == nil }), mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", mock.Anything, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	run := updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, 1, run.Updated)
+	assert.Equal(t, quote.StatusDone, q.Status)
+}
+
+func TestPurgeRuns_UsesRetention(t *testing.T) {
+	repo := new(mockRepo)
+	updater := cU.New(config.CronConfig{RunsRetention: 24 * time.Hour}, repo, nil, new(mockFetcher), log.NewZapLogger())
+
+	ctx := context.Background()
+	cutoff := time.Now().Add(-24 * time.Hour)
+	repo.On("PurgeRuns", ctx, mock.MatchedBy(func(before time.Time) bool {
+		return before.Sub(cutoff).Abs() < time.Second
+	})).Return(int64(3), nil)
+
+	updater.PurgeRuns(ctx)
+
+	repo.AssertExpectations(t)
+}
+
+func TestPurgeOutbox_UsesRetention(t *testing.T) {
+	repo := new(mockRepo)
+	updater := cU.New(config.CronConfig{OutboxRetention: 72 * time.Hour}, repo, nil, new(mockFetcher), log.NewZapLogger())
+
+	ctx := context.Background()
+	cutoff := time.Now().Add(-72 * time.Hour)
+	repo.On("PurgeOutbox", ctx, mock.MatchedBy(func(before time.Time) bool {
+		return before.Sub(cutoff).Abs() < time.Second
+	})).Return(int64(12), nil)
+
+	updater.PurgeOutbox(ctx)
+
+	repo.AssertExpectations(t)
+}
+
+func TestRun_RejectsInvalidLeaderSchedule(t *testing.T) {
+	updater := cU.New(config.CronConfig{
+		Schedule:      "@every 1m",
+		PurgeSchedule: "not a schedule",
+		RunsRetention: time.Hour,
+	}, new(mockRepo), nil, new(mockFetcher), log.NewZapLogger())
+
+	assert.Error(t, updater.Run())
+}
+
+func TestHandleQuoteRequested_DebouncesPerBase(t *testing.T) {
+	repo := new(mockRepo)
+	updater := cU.New(config.CronConfig{
+		InstanceID: "replica-1",
+		BatchSize:  10,
+		LeaseTTL:   time.Minute,
+		Notify:     config.NotifyConfig{Enabled: true, Debounce: 20 * time.Millisecond},
+	}, repo, nil, new(mockFetcher), log.NewZapLogger())
+	defer updater.Stop(context.Background())
+
+	repo.On("ClaimDueQuotes", mock.Anything, "replica-1", mock.AnythingOfType("time.Time"), time.Minute, 10, "EUR").Return([]*quote.Quote{}, nil)
+	repo.On("ClaimDueQuotes", mock.Anything, "replica-1", mock.AnythingOfType("time.Time"), time.Minute, 10, "USD").Return([]*quote.Quote{}, nil)
+	var runs atomic.Int32
+	repo.On("SaveRun", mock.Anything, mock.AnythingOfType("*quote.RunSummary")).Return(nil).
+		Run(func(mock.Arguments) { runs.Add(1) })
+
+	updater.HandleQuoteRequested("EUR/USD")
+	updater.HandleQuoteRequested("EUR/MXN")
+	updater.HandleQuoteRequested("EUR/USD")
+	updater.HandleQuoteRequested("USD/MXN")
+	updater.HandleQuoteRequested("garbage")
+
+	assert.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	repo.AssertNumberOfCalls(t, "ClaimDueQuotes", 2)
+	repo.AssertNumberOfCalls(t, "SaveRun", 2)
+}
+
+func TestUpdateQuotes_BoundsConcurrentGroups(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{Workers: 2}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	now := time.Now()
+	quotes := []*quote.Quote{
+		{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, CreatedAt: now},
+		{ID: "q2", Currency: "GBP/USD", Status: quote.StatusInProgress, CreatedAt: now},
+		{ID: "q3", Currency: "CHF/USD", Status: quote.StatusInProgress, CreatedAt: now},
+	}
+	var inFlight, peak atomic.Int32
+	onClaim(repo, ctx).Return(quotes, nil)
+	fetcher.On("FetchRates", ctx, mock.AnythingOfType("string"), []string{"USD"}).
+		Return(&exchange.Rates{Provider: "p", Values: map[string]decimal.Decimal{"USD": decimal.RequireFromString("1.1")}}, nil).
+		Run(func(mock.Arguments) {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(30 * time.Millisecond)
+			inFlight.Add(-1)
+		})
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	run := updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, 3, run.Updated)
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestUpdateQuotes_SkipsGroupsAfterTickDeadline(t *testing.T) {
+	repo := new(mockRepo)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{Workers: 1, TickDeadline: 20 * time.Millisecond}, repo, nil, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	now := time.Now()
+	quotes := []*quote.Quote{
+		{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress, CreatedAt: now},
+		{ID: "q2", Currency: "GBP/USD", Status: quote.StatusInProgress, CreatedAt: now},
+	}
+	repo.On("ClaimDueQuotes", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time"),
+		mock.AnythingOfType("time.Duration"), mock.AnythingOfType("int"), "").Return(quotes, nil)
+	fetcher.On("FetchRates", mock.Anything, mock.AnythingOfType("string"), []string{"USD"}).
+		Return(nil, context.DeadlineExceeded).
+		Run(func(args mock.Arguments) { <-args.Get(0).(context.Context).Done() })
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	run := updater.UpdateQuotes(ctx)
+
+	assert.Equal(t, 2, run.Skipped)
+	assert.Equal(t, 0, run.Failed)
+	fetcher.AssertNumberOfCalls(t, "FetchRates", 1)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestStop_CancelsRunsAfterDrainTimeout(t *testing.T) {
+	repo := new(mockRepo)
+	updater := cU.New(config.CronConfig{Notify: config.NotifyConfig{Debounce: time.Millisecond}},
+		repo, nil, new(mockFetcher), log.NewZapLogger())
+
+	claimed := make(chan struct{})
+	repo.On("ClaimDueQuotes", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time"),
+		mock.AnythingOfType("time.Duration"), mock.AnythingOfType("int"), "EUR").
+		Return(nil, context.Canceled).
+		Run(func(args mock.Arguments) {
+			close(claimed)
+			<-args.Get(0).(context.Context).Done()
+		})
+	repo.On("SaveRun", mock.Anything, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	updater.HandleQuoteRequested("EUR/USD")
+	<-claimed
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	updater.Stop(drainCtx)
+
+	repo.AssertNumberOfCalls(t, "SaveRun", 1)
+	updater.HandleQuoteRequested("EUR/MXN")
+	time.Sleep(10 * time.Millisecond)
+	repo.AssertNumberOfCalls(t, "ClaimDueQuotes", 1)
+}
+
+func TestMaterializeCandles_StoresClosedBucketsPerInterval(t *testing.T) {
+	candles := new(mockRateRepo)
+	updater := cU.New(config.CronConfig{Candles: config.CandlesConfig{
+		Schedule:  "@every 5m",
+		Intervals: []time.Duration{time.Minute, time.Hour},
+		Settle:    time.Minute,
+	}}, new(mockRepo), nil, new(mockFetcher), log.NewZapLogger())
+	updater.SetCandleRepository(candles)
+
+	ctx := context.Background()
+	cutoff := time.Now().Add(-time.Minute)
+	settled := mock.MatchedBy(func(before time.Time) bool {
+		return before.Sub(cutoff).Abs() < time.Second
+	})
+	candles.On("MaterializeCandles", ctx, time.Minute, settled).Return(int64(0), errors.New("db down"))
+	candles.On("MaterializeCandles", ctx, time.Hour, settled).Return(int64(2), nil)
+
+	updater.MaterializeCandles(ctx)
+
+	candles.AssertExpectations(t)
+}
+
+func TestPrewarm_RefreshesEnabledPairsPerBase(t *testing.T) {
+	repo := new(mockRepo)
+	pairs := new(mockPairs)
+	fetcher := new(mockFetcher)
+	updater := cU.New(config.CronConfig{InstanceID: "node-1"}, repo, pairs, fetcher, log.NewZapLogger())
+
+	ctx := context.Background()
+	pairs.On("List", ctx).Return([]*pair.Pair{
+		{Pair: "EUR/USD", Precision: 4, Enabled: true},
+		{Pair: "EUR/MXN", Precision: 2, Enabled: true},
+		{Pair: "USD/JPY", Precision: 2, Enabled: false},
+		{Pair: "GBP/USD", Precision: 4, Enabled: true},
+	}, nil)
+	pairs.On("Get", mock.Anything, mock.Anything).Return(nil, pair.ErrPairNotFound)
+	repo.On("ListPendingSystemCurrencies", ctx).Return([]string{"GBP/USD"}, nil)
+	var saved []*quote.Quote
+	repo.On("SaveLeased", ctx, mock.AnythingOfType("[]*quote.Quote")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).([]*quote.Quote) }).
+		Return(nil)
+	fetcher.On("FetchRates", ctx, "EUR", []string{"USD", "MXN"}).
+		Return(&exchange.Rates{Provider: "ecb", Values: map[string]decimal.Decimal{
+			"USD": decimal.RequireFromString("1.0823"),
+			"MXN": decimal.RequireFromString("19.75"),
+		}}, nil).Once()
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*quote.Quote")).Return(nil)
+	repo.On("SaveRun", ctx, mock.AnythingOfType("*quote.RunSummary")).Return(nil)
+
+	run := updater.Prewarm(ctx)
+
+	assert.Len(t, saved, 2)
+	for _, q := range saved {
+		assert.Equal(t, quote.OriginSystem, q.Origin)
+		assert.Equal(t, "node-1", q.LeaseOwner)
+		assert.Equal(t, quote.StatusDone, q.Status)
+	}
+	assert.Equal(t, 2, run.Updated)
+	assert.Equal(t, 1, run.Skipped)
+	assert.Equal(t, quote.OriginSystem, run.Origin)
+	fetcher.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
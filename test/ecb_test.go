@@ -0,0 +1,83 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"plata/internal/clients/exchange"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newECBServer(t *testing.T, fixture string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		http.ServeFile(w, r, "testdata/"+fixture)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newECB(url string) *exchange.ECB {
+	return exchange.NewECB(config.ProviderConfig{Name: "ecb", URL: url, Timeout: time.Second}, log.NewZapLogger())
+}
+
+func TestECB_EURBase(t *testing.T) {
+	srv := newECBServer(t, "eurofxref-daily.xml")
+
+	rates, err := newECB(srv.URL).FetchRates(context.Background(), "EUR", []string{"USD", "MXN", "XXX"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "ecb", rates.Provider)
+	assert.Equal(t, "1.125", rates.Values["USD"].String())
+	assert.Equal(t, "21.875", rates.Values["MXN"].String())
+	assert.NotContains(t, rates.Values, "XXX")
+}
+
+func TestECB_CrossBase(t *testing.T) {
+	srv := newECBServer(t, "eurofxref-daily.xml")
+
+	rates, err := newECB(srv.URL).FetchRates(context.Background(), "USD", []string{"MXN", "EUR"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "19.444444", rates.Values["MXN"].StringFixed(6))
+	assert.Equal(t, "0.888889", rates.Values["EUR"].StringFixed(6))
+}
+
+func TestECB_UsesLatestDayOfHistoricalFeed(t *testing.T) {
+	srv := newECBServer(t, "eurofxref-hist-90d.xml")
+
+	rates, err := newECB(srv.URL).FetchRates(context.Background(), "EUR", []string{"USD"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.125", rates.Values["USD"].String())
+}
+
+func TestECB_UnknownBase(t *testing.T) {
+	srv := newECBServer(t, "eurofxref-daily.xml")
+
+	_, err := newECB(srv.URL).FetchRates(context.Background(), "RUB", []string{"USD"})
+
+	assert.Error(t, err)
+}
+
+func TestNewFromConfig_SelectsECB(t *testing.T) {
+	srv := newECBServer(t, "eurofxref-daily.xml")
+
+	fetcher, err := exchange.NewFromConfig(config.ExchangeConfig{
+		Timeout:   time.Second,
+		Providers: []config.ProviderConfig{{Name: "ecb", Type: exchange.ProviderECB, URL: srv.URL}},
+	}, log.NewZapLogger())
+	require.NoError(t, err)
+
+	rates, err := fetcher.FetchRates(context.Background(), "EUR", []string{"GBP"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "0.8453", rates.Values["GBP"].String())
+}
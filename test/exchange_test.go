@@ -0,0 +1,138 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"plata/internal/clients/exchange"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProviderServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFailover_UsesNextProviderOnError(t *testing.T) {
+	down := newProviderServer(t, http.StatusServiceUnavailable, `{}`)
+	up := newProviderServer(t, http.StatusOK, `{"success": true, "rates": {"USD": 1.08, "MXN": 18.5}}`)
+
+	fetcher, err := exchange.NewFromConfig(config.ExchangeConfig{
+		Timeout: time.Second,
+		Providers: []config.ProviderConfig{
+			{Name: "primary", URL: down.URL},
+			{Name: "backup", URL: up.URL},
+		},
+	}, log.NewZapLogger())
+	require.NoError(t, err)
+
+	rates, err := fetcher.FetchRates(context.Background(), "EUR", []string{"USD", "MXN"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "backup", rates.Provider)
+	assert.Equal(t, "1.08", rates.Values["USD"].String())
+	require.Len(t, rates.Skipped, 1)
+	assert.Equal(t, "primary", rates.Skipped[0].Provider)
+}
+
+func TestFailover_AllProvidersFail(t *testing.T) {
+	first := newProviderServer(t, http.StatusInternalServerError, `{}`)
+	second := newProviderServer(t, http.StatusOK, `{"success": false}`)
+
+	fetcher, err := exchange.NewFromConfig(config.ExchangeConfig{
+		Timeout: time.Second,
+		Providers: []config.ProviderConfig{
+			{Name: "first", URL: first.URL},
+			{Name: "second", URL: second.URL},
+		},
+	}, log.NewZapLogger())
+	require.NoError(t, err)
+
+	rates, err := fetcher.FetchRates(context.Background(), "EUR", []string{"USD"})
+
+	assert.Nil(t, rates)
+	assert.ErrorContains(t, err, "provider first")
+	assert.ErrorContains(t, err, "provider second")
+}
+
+func TestNewFromConfig_NoProviders(t *testing.T) {
+	_, err := exchange.NewFromConfig(config.ExchangeConfig{}, log.NewZapLogger())
+	assert.ErrorIs(t, err, exchange.ErrNoProviders)
+}
+
+func TestMedian_DiscardsOutliers(t *testing.T) {
+	a := newProviderServer(t, http.StatusOK, `{"success": true, "rates": {"USD": 1.080}}`)
+	b := newProviderServer(t, http.StatusOK, `{"success": true, "rates": {"USD": 1.082}}`)
+	c := newProviderServer(t, http.StatusOK, `{"success": true, "rates": {"USD": 1.081}}`)
+	outlier := newProviderServer(t, http.StatusOK, `{"success": true, "rates": {"USD": 1.300}}`)
+	down := newProviderServer(t, http.StatusBadGateway, `{}`)
+
+	fetcher, err := exchange.NewFromConfig(config.ExchangeConfig{
+		Timeout:      time.Second,
+		Strategy:     exchange.StrategyMedian,
+		MaxDeviation: 0.01,
+		Providers: []config.ProviderConfig{
+			{Name: "a", URL: a.URL},
+			{Name: "b", URL: b.URL},
+			{Name: "c", URL: c.URL},
+			{Name: "outlier", URL: outlier.URL},
+			{Name: "down", URL: down.URL},
+		},
+	}, log.NewZapLogger())
+	require.NoError(t, err)
+
+	rates, err := fetcher.FetchRates(context.Background(), "EUR", []string{"USD"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.081", rates.Values["USD"].String())
+	assert.Equal(t, 3, rates.SourcesFor("USD"))
+	assert.Equal(t, "0.002", rates.Spread["USD"].String())
+	assert.Equal(t, "a,b,c", rates.ProviderFor("USD"))
+	assert.Equal(t, "a,b,c", rates.Provider)
+	require.Len(t, rates.Skipped, 1)
+	assert.Equal(t, "down", rates.Skipped[0].Provider)
+}
+
+func TestMedian_NotEnoughSources(t *testing.T) {
+	a := newProviderServer(t, http.StatusOK, `{"success": true, "rates": {"USD": 1.08}}`)
+	b := newProviderServer(t, http.StatusOK, `{"success": true, "rates": {"USD": 1.50}}`)
+
+	fetcher, err := exchange.NewFromConfig(config.ExchangeConfig{
+		Timeout:      time.Second,
+		Strategy:     exchange.StrategyMedian,
+		MaxDeviation: 0.01,
+		MinSources:   2,
+		Providers: []config.ProviderConfig{
+			{Name: "a", URL: a.URL},
+			{Name: "b", URL: b.URL},
+		},
+	}, log.NewZapLogger())
+	require.NoError(t, err)
+
+	rates, err := fetcher.FetchRates(context.Background(), "EUR", []string{"USD"})
+
+	require.NoError(t, err)
+	assert.NotContains(t, rates.Values, "USD")
+}
+
+func TestExchange_DecodesRatesExactly(t *testing.T) {
+	srv := newProviderServer(t, http.StatusOK, `{"success": true, "rates": {"USD": 1.0823000000000001}}`)
+	fetcher := exchange.New(config.ProviderConfig{Name: "p", URL: srv.URL}, log.NewZapLogger())
+
+	rates, err := fetcher.FetchRates(context.Background(), "EUR", []string{"USD"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0823000000000001", rates.Values["USD"].String())
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"encoding/json"
+	"plata/internal/app/hub"
+	"plata/internal/common/log"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"plata/internal/domain/quote"
+)
+
+func TestHub_PublishFiltersAndDeduplicates(t *testing.T) {
+	h := hub.New(log.NewZapLogger())
+	updates, cancel := h.Subscribe(func(q *quote.Quote) bool { return q.Currency == "EUR/USD" }, 4)
+	defer cancel()
+
+	now := time.Now()
+	usd := &quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusDone, UpdatedAt: now}
+	h.Publish(&quote.Quote{ID: "q2", Currency: "EUR/MXN", Status: quote.StatusDone, UpdatedAt: now})
+	h.Publish(usd)
+	h.Publish(usd)
+
+	assert.Equal(t, "q1", (<-updates).ID)
+	assert.Empty(t, updates)
+}
+
+func TestHub_DisconnectsSlowSubscriber(t *testing.T) {
+	h := hub.New(log.NewZapLogger())
+	updates, cancel := h.Subscribe(nil, 1)
+	defer cancel()
+
+	now := time.Now()
+	h.Publish(&quote.Quote{ID: "q1", UpdatedAt: now})
+	h.Publish(&quote.Quote{ID: "q2", UpdatedAt: now})
+
+	assert.Equal(t, "q1", (<-updates).ID)
+	_, ok := <-updates
+	assert.False(t, ok)
+}
+
+func TestHub_HandleNotificationDecodesQuote(t *testing.T) {
+	h := hub.New(log.NewZapLogger())
+	updates, cancel := h.Subscribe(nil, 1)
+	defer cancel()
+
+	sent := &quote.Quote{
+		ID:        "q1",
+		Currency:  "EUR/USD",
+		Amount:    decimal.RequireFromString("1.0823"),
+		Scale:     6,
+		Status:    quote.StatusDone,
+		UpdatedAt: time.Now(),
+	}
+	payload, err := json.Marshal(sent)
+	require.NoError(t, err)
+
+	h.HandleNotification(string(payload))
+	h.Publish(sent)
+
+	got := <-updates
+	assert.Equal(t, quote.StatusDone, got.Status)
+	assert.Equal(t, "1.082300", got.Amount.StringFixed(got.Scale))
+	assert.Equal(t, int32(6), got.Scale)
+	assert.Empty(t, updates)
+}
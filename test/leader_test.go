@@ -0,0 +1,166 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	cU "plata/internal/app/cron"
+	"plata/internal/app/leader"
+)
+
+type mockLeaderLocks struct {
+	mock.Mock
+}
+
+func (m *mockLeaderLocks) TryLock(ctx context.Context, key int64, name string) (bool, error) {
+	args := m.Called(ctx, key, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockLeaderLocks) Held(ctx context.Context, key int64) (bool, error) {
+	args := m.Called(ctx, key)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockLeaderLocks) Unlock(ctx context.Context, key int64) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *mockLeaderLocks) Close() {
+	m.Called()
+}
+
+func (m *mockLeaderLocks) Holder(ctx context.Context, key int64) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+// leaderChanges records the leadership callbacks of an elector.
+type leaderChanges struct {
+	mu      sync.Mutex
+	changes []bool
+}
+
+func (l *leaderChanges) record(leader bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = append(l.changes, leader)
+}
+
+func (l *leaderChanges) get() []bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]bool(nil), l.changes...)
+}
+
+func newTestElector(locks *mockLeaderLocks) (*leader.Elector, *leaderChanges) {
+	elector := leader.New(config.CronConfig{
+		InstanceID: "node-1",
+		Leader:     config.LeaderConfig{Enabled: true, LockKey: 42, RenewInterval: 5 * time.Millisecond},
+	}, locks, log.NewZapLogger())
+	changes := &leaderChanges{}
+	elector.OnChange(changes.record)
+	return elector, changes
+}
+
+func TestElector_AcquiresAndReleasesLock(t *testing.T) {
+	locks := new(mockLeaderLocks)
+	elector, changes := newTestElector(locks)
+
+	locks.On("TryLock", mock.Anything, int64(42), "node-1").Return(true, nil).Once()
+	locks.On("Held", mock.Anything, int64(42)).Return(true, nil).Maybe()
+	locks.On("Unlock", mock.Anything, int64(42)).Return(nil).Once()
+
+	elector.Start(context.Background())
+	assert.Eventually(t, elector.IsLeader, time.Second, time.Millisecond)
+	elector.Stop()
+
+	assert.False(t, elector.IsLeader())
+	assert.Equal(t, []bool{true, false}, changes.get())
+	locks.AssertExpectations(t)
+}
+
+func TestElector_WaitsWhileAnotherInstanceLeads(t *testing.T) {
+	locks := new(mockLeaderLocks)
+	elector, changes := newTestElector(locks)
+
+	var attempts atomic.Int32
+	locks.On("TryLock", mock.Anything, int64(42), "node-1").Run(func(mock.Arguments) { attempts.Add(1) }).Return(false, nil)
+
+	elector.Start(context.Background())
+	assert.Eventually(t, func() bool { return attempts.Load() >= 3 }, time.Second, time.Millisecond)
+	elector.Stop()
+
+	assert.Empty(t, changes.get())
+	locks.AssertNotCalled(t, "Unlock", mock.Anything, mock.Anything)
+}
+
+func TestElector_LosesLeadershipWhenSessionDrops(t *testing.T) {
+	locks := new(mockLeaderLocks)
+	elector, changes := newTestElector(locks)
+
+	locks.On("TryLock", mock.Anything, int64(42), "node-1").Return(true, nil).Once()
+	locks.On("Held", mock.Anything, int64(42)).Return(false, errors.New("connection reset")).Once()
+	locks.On("Close").Return().Once()
+	locks.On("TryLock", mock.Anything, int64(42), "node-1").Return(false, nil)
+
+	elector.Start(context.Background())
+	assert.Eventually(t, func() bool { return len(changes.get()) == 2 }, time.Second, time.Millisecond)
+	elector.Stop()
+
+	assert.Equal(t, []bool{true, false}, changes.get())
+	assert.False(t, elector.IsLeader())
+	locks.AssertExpectations(t)
+	locks.AssertNotCalled(t, "Unlock", mock.Anything, mock.Anything)
+}
+
+func TestElector_DisabledActsAsLeader(t *testing.T) {
+	locks := new(mockLeaderLocks)
+	elector := leader.New(config.CronConfig{InstanceID: "node-1"}, locks, log.NewZapLogger())
+
+	elector.Start(context.Background())
+	id, err := elector.Leader(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "node-1", id)
+	assert.True(t, elector.IsLeader())
+	elector.Stop()
+	assert.False(t, elector.IsLeader())
+	locks.AssertNotCalled(t, "TryLock", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetLeader_RegistersAndRemovesLeaderJobs(t *testing.T) {
+	updater := cU.New(config.CronConfig{
+		PurgeSchedule:   "@every 1h",
+		PrewarmSchedule: "@every 10m",
+		RunsRetention:   time.Hour,
+		OutboxRetention: time.Hour,
+	}, new(mockRepo), new(mockPairs), new(mockFetcher), log.NewZapLogger())
+
+	assert.Empty(t, updater.LeaderJobs())
+
+	updater.SetLeader(true)
+	jobs := []string{"prewarm pairs", "purge outbox", "purge update runs"}
+	assert.Equal(t, jobs, updater.LeaderJobs())
+
+	updater.SetLeader(true)
+	assert.Equal(t, jobs, updater.LeaderJobs())
+
+	updater.SetLeader(false)
+	assert.Empty(t, updater.LeaderJobs())
+
+	updater.SetLeader(false)
+	assert.Empty(t, updater.LeaderJobs())
+
+	updater.SetLeader(true)
+	assert.Equal(t, jobs, updater.LeaderJobs())
+}
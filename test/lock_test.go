@@ -0,0 +1,184 @@
+package test
+
+import (
+	"context"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"plata/internal/domain/pair"
+	"plata/internal/domain/quote"
+	"plata/internal/domain/rate"
+	ls "plata/internal/services/lock"
+)
+
+type mockLockRepo struct {
+	mock.Mock
+}
+
+func (m *mockLockRepo) Create(ctx context.Context, l *quote.Lock) error {
+	args := m.Called(ctx, l)
+	return args.Error(0)
+}
+
+func (m *mockLockRepo) GetByIdempotencyKey(ctx context.Context, key string) (*quote.Lock, error) {
+	args := m.Called(ctx, key)
+	if l, ok := args.Get(0).(*quote.Lock); ok {
+		return l, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockLockRepo) Consume(ctx context.Context, token string, now time.Time) (*quote.Lock, error) {
+	args := m.Called(ctx, token, now)
+	if l, ok := args.Get(0).(*quote.Lock); ok {
+		return l, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func newLockService(repo *mockLockRepo, rates *mockRateRepo, pairs *mockPairs) *ls.Service {
+	return ls.New(config.LockConfig{
+		DefaultTTL:   30 * time.Second,
+		MaxTTL:       5 * time.Minute,
+		MaxRateAge:   5 * time.Minute,
+		MaxMarkupBps: 500,
+	}, repo, rates, pairs, log.NewZapLogger())
+}
+
+func TestLock_SnapshotsLatestRateWithMarkup(t *testing.T) {
+	repo, rates, pairs := new(mockLockRepo), new(mockRateRepo), new(mockPairs)
+	service := newLockService(repo, rates, pairs)
+
+	ctx := context.Background()
+	pairs.On("Get", ctx, "EUR/USD").Return(&pair.Pair{Pair: "EUR/USD", Enabled: true}, nil)
+	rates.On("GetAt", ctx, "EUR/USD", mock.AnythingOfType("time.Time")).
+		Return(storedRate("EUR/USD", "1.0823", "q1", time.Now().UTC().Add(-time.Minute)), nil)
+	repo.On("Create", ctx, mock.AnythingOfType("*quote.Lock")).Return(nil)
+
+	l, err := service.Lock(ctx, "EUR/USD", time.Minute, 100, "")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, l.Token)
+	assert.Equal(t, "q1", l.QuoteID)
+	assert.Equal(t, "1.093123", l.Rate.StringFixed(l.Scale))
+	assert.Equal(t, time.Minute, l.ExpiresAt.Sub(l.CreatedAt))
+	repo.AssertExpectations(t)
+}
+
+func TestLock_ReturnsExistingLockForIdempotencyKey(t *testing.T) {
+	repo, rates, pairs := new(mockLockRepo), new(mockRateRepo), new(mockPairs)
+	service := newLockService(repo, rates, pairs)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	existing := &quote.Lock{Token: "t1", Currency: "EUR/USD", CreatedAt: now, ExpiresAt: now.Add(30 * time.Second)}
+	pairs.On("Get", ctx, "EUR/USD").Return(&pair.Pair{Pair: "EUR/USD", Enabled: true}, nil)
+	repo.On("GetByIdempotencyKey", ctx, "key-1").Return(existing, nil)
+
+	l, err := service.Lock(ctx, "EUR/USD", 0, 0, "key-1")
+
+	assert.NoError(t, err)
+	assert.Same(t, existing, l)
+
+	_, err = service.Lock(ctx, "EUR/USD", 0, 25, "key-1")
+
+	assert.ErrorIs(t, err, quote.ErrIdempotencyConflict)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLock_ReturnsLockOfConcurrentRequestWithSameKey(t *testing.T) {
+	repo, rates, pairs := new(mockLockRepo), new(mockRateRepo), new(mockPairs)
+	service := newLockService(repo, rates, pairs)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	winner := &quote.Lock{Token: "t1", Currency: "EUR/USD", MarkupBps: 10, CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+	pairs.On("Get", ctx, "EUR/USD").Return(&pair.Pair{Pair: "EUR/USD", Enabled: true}, nil)
+	repo.On("GetByIdempotencyKey", ctx, "key-1").Return(nil, nil).Once()
+	rates.On("GetAt", ctx, "EUR/USD", mock.Anything).Return(storedRate("EUR/USD", "1.0823", "q1", now), nil)
+	repo.On("Create", ctx, mock.AnythingOfType("*quote.Lock")).Return(quote.ErrLockExists)
+	repo.On("GetByIdempotencyKey", ctx, "key-1").Return(winner, nil).Once()
+
+	l, err := service.Lock(ctx, "EUR/USD", time.Minute, 10, "key-1")
+
+	assert.NoError(t, err)
+	assert.Same(t, winner, l)
+}
+
+func TestLock_DefaultsMaxMarkup(t *testing.T) {
+	repo, rates, pairs := new(mockLockRepo), new(mockRateRepo), new(mockPairs)
+	service := ls.New(config.LockConfig{}, repo, rates, pairs, log.NewZapLogger())
+
+	ctx := context.Background()
+	pairs.On("Get", ctx, "EUR/USD").Return(&pair.Pair{Pair: "EUR/USD", Enabled: true}, nil)
+	rates.On("GetAt", ctx, "EUR/USD", mock.Anything).Return(storedRate("EUR/USD", "1.0823", "q1", time.Now().UTC()), nil)
+	repo.On("Create", ctx, mock.AnythingOfType("*quote.Lock")).Return(nil)
+
+	_, err := service.Lock(ctx, "EUR/USD", 0, 25, "")
+
+	assert.NoError(t, err)
+}
+
+func TestLock_RejectsStaleRate(t *testing.T) {
+	repo, rates, pairs := new(mockLockRepo), new(mockRateRepo), new(mockPairs)
+	service := newLockService(repo, rates, pairs)
+
+	ctx := context.Background()
+	pairs.On("Get", ctx, "EUR/USD").Return(&pair.Pair{Pair: "EUR/USD", Enabled: true}, nil)
+	rates.On("GetAt", ctx, "EUR/USD", mock.Anything).
+		Return(storedRate("EUR/USD", "1.0823", "q1", time.Now().UTC().Add(-time.Hour)), nil)
+
+	_, err := service.Lock(ctx, "EUR/USD", 0, 0, "")
+
+	assert.ErrorIs(t, err, quote.ErrRateUnavailable)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLock_RejectsNoRateAndInvalidTerms(t *testing.T) {
+	repo, rates, pairs := new(mockLockRepo), new(mockRateRepo), new(mockPairs)
+	service := newLockService(repo, rates, pairs)
+
+	ctx := context.Background()
+	pairs.On("Get", ctx, "EUR/USD").Return(&pair.Pair{Pair: "EUR/USD", Enabled: true}, nil)
+	rates.On("GetAt", ctx, "EUR/USD", mock.Anything).Return(nil, rate.ErrRateNotFound)
+
+	_, err := service.Lock(ctx, "EUR/USD", 0, 0, "")
+	assert.ErrorIs(t, err, quote.ErrRateUnavailable)
+
+	_, err = service.Lock(ctx, "EUR/USD", time.Hour, 0, "")
+	assert.ErrorIs(t, err, quote.ErrInvalidLock)
+
+	_, err = service.Lock(ctx, "EUR/USD", 0, 1000, "")
+	assert.ErrorIs(t, err, quote.ErrInvalidLock)
+}
+
+func TestConsume_PropagatesLockState(t *testing.T) {
+	repo := new(mockLockRepo)
+	service := newLockService(repo, new(mockRateRepo), new(mockPairs))
+
+	ctx := context.Background()
+	consumedAt := time.Now()
+	repo.On("Consume", ctx, "t1", mock.AnythingOfType("time.Time")).Return(&quote.Lock{Token: "t1", ConsumedAt: &consumedAt}, nil)
+	repo.On("Consume", ctx, "t2", mock.AnythingOfType("time.Time")).Return(nil, quote.ErrLockConsumed)
+	repo.On("Consume", ctx, "t3", mock.AnythingOfType("time.Time")).Return(nil, quote.ErrLockExpired)
+
+	l, err := service.Consume(ctx, "t1")
+	assert.NoError(t, err)
+	assert.NotNil(t, l.ConsumedAt)
+
+	_, err = service.Consume(ctx, "t2")
+	assert.ErrorIs(t, err, quote.ErrLockConsumed)
+
+	_, err = service.Consume(ctx, "t3")
+	assert.ErrorIs(t, err, quote.ErrLockExpired)
+}
+
+func TestApplyMarkup_RoundsToScale(t *testing.T) {
+	assert.Equal(t, "18.7345", quote.ApplyMarkup(decimal.RequireFromString("18.7345"), 0, 4).String())
+	assert.Equal(t, "18.7532", quote.ApplyMarkup(decimal.RequireFromString("18.7345"), 10, 4).String())
+}
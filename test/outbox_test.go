@@ -0,0 +1,142 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"plata/internal/app/outbox"
+	"plata/internal/domain/quote"
+)
+
+type mockEventRepo struct {
+	mock.Mock
+	pending [][]*quote.Event
+}
+
+func (m *mockEventRepo) PublishPending(ctx context.Context, owner string, lease time.Duration, limit int, publish func(ctx context.Context, events []*quote.Event) error) (int, error) {
+	args := m.Called(ctx, limit)
+	if len(m.pending) == 0 {
+		return 0, args.Error(0)
+	}
+	batch := m.pending[0]
+	if err := publish(ctx, batch); err != nil {
+		return 0, err
+	}
+	m.pending = m.pending[1:]
+	return len(batch), args.Error(0)
+}
+
+type mockPublisher struct {
+	mock.Mock
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, events []*quote.Event) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *mockPublisher) Close() error {
+	return nil
+}
+
+func newEvents(ids ...string) []*quote.Event {
+	events := make([]*quote.Event, len(ids))
+	for i, id := range ids {
+		events[i] = &quote.Event{
+			ID:         id,
+			Type:       quote.EventCompleted,
+			QuoteID:    "q-" + id,
+			OccurredAt: time.Now(),
+			Quote:      &quote.Quote{ID: "q-" + id, Currency: "EUR/USD", Status: quote.StatusDone},
+		}
+	}
+	return events
+}
+
+func TestRelay_PublishesBatchesUntilDrained(t *testing.T) {
+	repo := &mockEventRepo{pending: [][]*quote.Event{newEvents("e1", "e2"), newEvents("e3")}}
+	publisher := new(mockPublisher)
+	relay := outbox.New(config.EventsConfig{BatchSize: 2}, "replica-1", repo, publisher, log.NewZapLogger())
+
+	ctx := context.Background()
+	repo.On("PublishPending", ctx, 2).Return(nil)
+	publisher.On("Publish", ctx, mock.Anything).Return(nil)
+
+	assert.Equal(t, 3, relay.RelayPending(ctx))
+	publisher.AssertNumberOfCalls(t, "Publish", 2)
+}
+
+func TestRelay_KeepsEventsPendingWhenPublishFails(t *testing.T) {
+	repo := &mockEventRepo{pending: [][]*quote.Event{newEvents("e1")}}
+	publisher := new(mockPublisher)
+	relay := outbox.New(config.EventsConfig{BatchSize: 10}, "replica-1", repo, publisher, log.NewZapLogger())
+
+	ctx := context.Background()
+	repo.On("PublishPending", ctx, 10).Return(nil)
+	publisher.On("Publish", ctx, mock.Anything).Return(errors.New("sink down")).Once()
+	publisher.On("Publish", ctx, mock.Anything).Return(nil)
+
+	assert.Equal(t, 0, relay.RelayPending(ctx))
+	assert.Len(t, repo.pending, 1)
+	assert.Equal(t, 1, relay.RelayPending(ctx))
+	assert.Empty(t, repo.pending)
+}
+
+func TestNDJSONPublisher_AppendsOneEventPerLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events", "quotes.ndjson")
+	publisher, err := outbox.NewPublisher(config.EventsConfig{Publisher: outbox.PublisherNDJSON, Path: path})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, publisher.Publish(ctx, newEvents("e1", "e2")))
+	require.NoError(t, publisher.Publish(ctx, newEvents("e3")))
+	require.NoError(t, publisher.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e quote.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		ids = append(ids, e.ID)
+		assert.Equal(t, quote.StatusDone, e.Quote.Status)
+	}
+	assert.Equal(t, []string{"e1", "e2", "e3"}, ids)
+}
+
+func TestHTTPPublisher_FailsOnErrorStatus(t *testing.T) {
+	var contentType string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer sink.Close()
+
+	publisher, err := outbox.NewPublisher(config.EventsConfig{Publisher: outbox.PublisherHTTP, URL: sink.URL})
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), newEvents("e1"))
+
+	assert.ErrorContains(t, err, "503")
+	assert.Equal(t, "application/x-ndjson", contentType)
+}
+
+func TestNewPublisher_RejectsUnknownType(t *testing.T) {
+	_, err := outbox.NewPublisher(config.EventsConfig{Publisher: "kafka"})
+	assert.ErrorIs(t, err, outbox.ErrUnknownPublisher)
+}
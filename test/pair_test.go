@@ -0,0 +1,91 @@
+package test
+
+import (
+	"context"
+	"plata/internal/common/log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"plata/internal/domain/pair"
+	ps "plata/internal/services/pair"
+)
+
+type mockPairRepo struct {
+	mock.Mock
+}
+
+func (m *mockPairRepo) List(ctx context.Context) ([]*pair.Pair, error) {
+	args := m.Called(ctx)
+	if pairs, ok := args.Get(0).([]*pair.Pair); ok {
+		return pairs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockPairRepo) Create(ctx context.Context, p *pair.Pair) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *mockPairRepo) SetEnabled(ctx context.Context, name string, enabled bool) (*pair.Pair, error) {
+	args := m.Called(ctx, name, enabled)
+	if p, ok := args.Get(0).(*pair.Pair); ok {
+		return p, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestPairService_GetUsesCache(t *testing.T) {
+	repo := new(mockPairRepo)
+	service := ps.New(repo, []string{"ecb"}, time.Minute, log.NewZapLogger())
+	ctx := context.Background()
+
+	repo.On("List", ctx).Return([]*pair.Pair{{Pair: "EUR/USD", Enabled: true}}, nil).Once()
+
+	p, err := service.Get(ctx, "EUR/USD")
+	require.NoError(t, err)
+	assert.True(t, p.Enabled)
+
+	_, err = service.Get(ctx, "USD/JPY")
+	assert.ErrorIs(t, err, pair.ErrPairNotFound)
+
+	repo.AssertNumberOfCalls(t, "List", 1)
+}
+
+func TestPairService_SetEnabledInvalidatesCache(t *testing.T) {
+	repo := new(mockPairRepo)
+	service := ps.New(repo, []string{"ecb"}, time.Minute, log.NewZapLogger())
+	ctx := context.Background()
+
+	repo.On("List", ctx).Return([]*pair.Pair{{Pair: "EUR/USD", Enabled: true}}, nil).Once()
+	repo.On("SetEnabled", ctx, "EUR/USD", false).Return(&pair.Pair{Pair: "EUR/USD", Enabled: false}, nil)
+	repo.On("List", ctx).Return([]*pair.Pair{{Pair: "EUR/USD", Enabled: false}}, nil).Once()
+
+	_, err := service.Get(ctx, "EUR/USD")
+	require.NoError(t, err)
+	_, err = service.SetEnabled(ctx, "EUR/USD", false)
+	require.NoError(t, err)
+
+	p, err := service.Get(ctx, "EUR/USD")
+	require.NoError(t, err)
+	assert.False(t, p.Enabled)
+	repo.AssertNumberOfCalls(t, "List", 2)
+}
+
+func TestPairService_AddValidates(t *testing.T) {
+	repo := new(mockPairRepo)
+	service := ps.New(repo, []string{"ecb"}, time.Minute, log.NewZapLogger())
+
+	_, err := service.Add(context.Background(), &pair.Pair{Pair: "eurusd"})
+	assert.ErrorIs(t, err, pair.ErrInvalidPair)
+
+	_, err = service.Add(context.Background(), &pair.Pair{Pair: "EUR/USD", Precision: 9})
+	assert.ErrorIs(t, err, pair.ErrInvalidPrecision)
+
+	_, err = service.Add(context.Background(), &pair.Pair{Pair: "EUR/USD", Provider: "ecbb"})
+	assert.ErrorIs(t, err, pair.ErrUnknownProvider)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// postgresDSNEnv names the database the repository tests run against, they are
+// skipped when it is not set.
+const postgresDSNEnv = "PLATA_TEST_POSTGRES_DSN"
+
+// newTestDB returns a connection to a fresh schema holding every migration, dropped
+// once the test ends. The pool keeps a single connection so search_path sticks.
+func newTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", postgresDSNEnv)
+	}
+	db, err := sqlx.Connect("postgres", dsn)
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("plata_test_%d", time.Now().UnixNano())
+	_, err = db.Exec(`CREATE SCHEMA ` + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		db.Close()
+	})
+	_, err = db.Exec(`SET search_path TO ` + schema)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob("../migrations/*.up.sql")
+	require.NoError(t, err)
+	sort.Strings(files)
+	for _, f := range files {
+		script, err := os.ReadFile(f)
+		require.NoError(t, err)
+		_, err = db.Exec(string(script))
+		require.NoError(t, err, f)
+	}
+	return db
+}
@@ -0,0 +1,159 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"plata/internal/common/log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"plata/internal/domain/pricing"
+	"plata/internal/domain/quote"
+	prs "plata/internal/services/pricing"
+)
+
+type mockRuleRepo struct {
+	mock.Mock
+}
+
+func (m *mockRuleRepo) List(ctx context.Context) ([]*pricing.Rule, error) {
+	args := m.Called(ctx)
+	if rules, ok := args.Get(0).([]*pricing.Rule); ok {
+		return rules, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRuleRepo) Create(ctx context.Context, r *pricing.Rule) error {
+	args := m.Called(ctx, r)
+	return args.Error(0)
+}
+
+func (m *mockRuleRepo) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestSelect_PrefersMostSpecificRuleAndHighestTier(t *testing.T) {
+	rules := []*pricing.Rule{
+		{ID: 1, MarkupBps: 50},
+		{ID: 2, Pair: "EUR/USD", MarkupBps: 30},
+		{ID: 3, Pair: "EUR/USD", MinAmount: decimal.NewFromInt(10000), MarkupBps: 20},
+		{ID: 4, Tenant: "acme", MarkupBps: 10},
+		{ID: 5, Tenant: "acme", Pair: "EUR/USD", MinAmount: decimal.NewFromInt(1000000), MarkupBps: 5},
+	}
+
+	cases := []struct {
+		tenant, pair string
+		amount       int64
+		want         int64
+	}{
+		{"", "GBP/USD", 0, 1},
+		{"", "EUR/USD", 500, 2},
+		{"", "EUR/USD", 50000, 3},
+		{"acme", "EUR/USD", 50000, 4},
+		{"acme", "EUR/USD", 2000000, 5},
+		{"other", "EUR/USD", 50000, 3},
+	}
+	for _, c := range cases {
+		rule := pricing.Select(rules, c.tenant, c.pair, decimal.NewFromInt(c.amount))
+
+		assert.Equal(t, c.want, rule.ID, "%s %s %d", c.tenant, c.pair, c.amount)
+	}
+	assert.Nil(t, pricing.Select(rules[1:4], "", "GBP/USD", decimal.Zero))
+}
+
+func TestApply_WidensMidByBpsAndPips(t *testing.T) {
+	mid := decimal.RequireFromString("1.082300")
+
+	p := pricing.Apply(&pricing.Rule{ID: 7, MarkupBps: 10, MarkupPips: decimal.RequireFromString("0.5")}, "EUR/USD", mid, 6)
+
+	assert.Equal(t, "1.081167", p.Bid.StringFixed(6))
+	assert.Equal(t, "1.083433", p.Ask.StringFixed(6))
+	assert.Equal(t, int64(7), p.RuleID)
+
+	yen := pricing.Apply(&pricing.Rule{MarkupPips: decimal.NewFromInt(2)}, "EUR/JPY", decimal.RequireFromString("162.50"), 2)
+	assert.Equal(t, "162.48", yen.Bid.StringFixed(2))
+	assert.Equal(t, "162.52", yen.Ask.StringFixed(2))
+
+	wide := pricing.Apply(&pricing.Rule{MarkupBps: pricing.MaxMarkupBps, MarkupPips: decimal.NewFromInt(50)}, "EUR/USD", mid, 6)
+	assert.True(t, wide.Bid.IsPositive())
+}
+
+func TestValidate_RejectsValuesTheStoreCannotKeep(t *testing.T) {
+	cases := map[string]*pricing.Rule{
+		"min_amount scale":  {MinAmount: decimal.RequireFromString("0.005")},
+		"markup_pips scale": {MarkupPips: decimal.RequireFromString("0.125")},
+		"min_amount range":  {MinAmount: decimal.New(1, 18)},
+		"markup_pips range": {MarkupPips: decimal.New(1, 8)},
+		"full markup":       {MarkupBps: 10000},
+		"long tenant":       {Tenant: strings.Repeat("t", 65)},
+	}
+	for name, rule := range cases {
+		assert.ErrorIs(t, pricing.Validate(rule), pricing.ErrInvalidRule, name)
+	}
+	assert.NoError(t, pricing.Validate(&pricing.Rule{Tenant: "acme", MinAmount: decimal.RequireFromString("10000.50"), MarkupPips: decimal.RequireFromString("0.50")}))
+}
+
+func TestPrice_ReturnsPricedCopyAndCachesRules(t *testing.T) {
+	repo := new(mockRuleRepo)
+	service := prs.New(repo, time.Minute, log.NewZapLogger())
+
+	ctx := context.Background()
+	repo.On("List", ctx).Return([]*pricing.Rule{{ID: 3, Tenant: "acme", MarkupBps: 100}}, nil).Once()
+	q := &quote.Quote{ID: "q1", Currency: "EUR/USD", Amount: decimal.RequireFromString("1.5"), Scale: 4, Status: quote.StatusDone}
+
+	priced, err := service.Price(ctx, q, "acme", decimal.Zero)
+	assert.NoError(t, err)
+	_, err = service.Price(ctx, q, "acme", decimal.Zero)
+	assert.NoError(t, err)
+
+	assert.Nil(t, q.Price)
+	data, _ := json.Marshal(priced)
+	assert.Contains(t, string(data), `"mid":"1.5000"`)
+	assert.Contains(t, string(data), `"bid":"1.4850"`)
+	assert.Contains(t, string(data), `"ask":"1.5150"`)
+	assert.Contains(t, string(data), `"rule_id":3`)
+	repo.AssertExpectations(t)
+}
+
+func TestPrice_LeavesPendingQuotesUnpriced(t *testing.T) {
+	repo := new(mockRuleRepo)
+	service := prs.New(repo, time.Minute, log.NewZapLogger())
+
+	q := &quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusInProgress}
+
+	priced, err := service.Price(context.Background(), q, "", decimal.Zero)
+
+	assert.NoError(t, err)
+	assert.Nil(t, priced.Price)
+	data, _ := json.Marshal(priced)
+	assert.NotContains(t, string(data), `"bid"`)
+	repo.AssertNotCalled(t, "List", mock.Anything)
+}
+
+func TestAddPricingRule_ValidatesAndInvalidatesCache(t *testing.T) {
+	repo := new(mockRuleRepo)
+	service := prs.New(repo, time.Hour, log.NewZapLogger())
+
+	ctx := context.Background()
+	_, err := service.Add(ctx, &pricing.Rule{Pair: "eurusd"})
+	assert.ErrorIs(t, err, pricing.ErrInvalidRule)
+
+	repo.On("List", ctx).Return([]*pricing.Rule{}, nil).Twice()
+	repo.On("Create", ctx, mock.AnythingOfType("*pricing.Rule")).Return(nil)
+	q := &quote.Quote{Currency: "EUR/USD", Amount: decimal.NewFromInt(1), Status: quote.StatusDone}
+
+	_, err = service.Price(ctx, q, "", decimal.Zero)
+	assert.NoError(t, err)
+	_, err = service.Add(ctx, &pricing.Rule{Pair: "EUR/USD", MarkupBps: 10})
+	assert.NoError(t, err)
+	_, err = service.Price(ctx, q, "", decimal.Zero)
+	assert.NoError(t, err)
+
+	repo.AssertExpectations(t)
+}
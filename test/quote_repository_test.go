@@ -0,0 +1,60 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"plata/internal/domain/quote"
+	qr "plata/internal/repository/quote"
+)
+
+func TestQuoteRepository_UpdateRejectsLateWriteAfterCompletion(t *testing.T) {
+	db := newTestDB(t)
+	repo := qr.New(db, db, true)
+	ctx := context.Background()
+
+	now := time.Now()
+	q := &quote.Quote{
+		ID:        uuid.NewString(),
+		Currency:  "EUR/USD",
+		Scale:     4,
+		Status:    quote.StatusInProgress,
+		Origin:    quote.OriginRequest,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, repo.Save(ctx, q))
+
+	// worker-1 claims with a lease that has already run out when worker-2 claims.
+	slow, err := repo.ClaimDueQuotes(ctx, "worker-1", now, -time.Second, 1, "")
+	require.NoError(t, err)
+	require.Len(t, slow, 1)
+	fast, err := repo.ClaimDueQuotes(ctx, "worker-2", now, time.Minute, 1, "")
+	require.NoError(t, err)
+	require.Len(t, fast, 1)
+
+	fast[0].Status = quote.StatusDone
+	fast[0].Amount = decimal.RequireFromString("1.0823")
+	fast[0].UpdatedAt = time.Now()
+	require.NoError(t, repo.Update(ctx, fast[0]))
+
+	slow[0].Status = quote.StatusFailed
+	slow[0].ErrorCode = quote.ErrorCodeProvider
+	slow[0].UpdatedAt = time.Now()
+	assert.ErrorIs(t, repo.Update(ctx, slow[0]), quote.ErrLeaseLost)
+
+	stored, err := repo.GetByID(ctx, q.ID)
+	require.NoError(t, err)
+	assert.Equal(t, quote.StatusDone, stored.Status)
+
+	var rates, events int
+	require.NoError(t, db.Get(&rates, `SELECT COUNT(*) FROM quote_rates WHERE quote_id = $1`, q.ID))
+	require.NoError(t, db.Get(&events, `SELECT COUNT(*) FROM quote_events WHERE quote_id = $1`, q.ID))
+	assert.Equal(t, 1, rates)
+	assert.Equal(t, 2, events)
+}
@@ -2,22 +2,47 @@ package test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"plata/internal/app/hub"
+	"plata/internal/clients/exchange"
 	"plata/internal/common/log"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"plata/internal/domain/pair"
 	"plata/internal/domain/quote"
+	"plata/internal/domain/webhook"
 	qs "plata/internal/services/quote"
+	ws "plata/internal/services/webhook"
 )
 
 type mockRepo struct {
 	mock.Mock
 }
 
-func (m *mockRepo) GetInProgressQuotes(ctx context.Context) ([]*quote.Quote, error) {
-	args := m.Called(ctx)
+func (m *mockRepo) GetInProgressQuotes(ctx context.Context, now time.Time) ([]*quote.Quote, error) {
+	args := m.Called(ctx, now)
+	if quotes, ok := args.Get(0).([]*quote.Quote); ok {
+		return quotes, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRepo) ListDoneAfter(ctx context.Context, currencies []string, afterID string, limit int) ([]*quote.Quote, error) {
+	args := m.Called(ctx, currencies, afterID, limit)
+	if quotes, ok := args.Get(0).([]*quote.Quote); ok {
+		return quotes, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRepo) ClaimDueQuotes(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int, base string) ([]*quote.Quote, error) {
+	args := m.Called(ctx, owner, now, lease, limit, base)
 	if quotes, ok := args.Get(0).([]*quote.Quote); ok {
 		return quotes, args.Error(1)
 	}
@@ -40,6 +65,14 @@ func (m *mockRepo) GetLatestByCurrency(ctx context.Context, currency string) (*q
 	return nil, args.Error(1)
 }
 
+func (m *mockRepo) GetPendingByCurrency(ctx context.Context, currency string) (*quote.Quote, error) {
+	args := m.Called(ctx, currency)
+	if quote, ok := args.Get(0).(*quote.Quote); ok {
+		return quote, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *mockRepo) GetByIdempotencyKey(ctx context.Context, key string) (*quote.Quote, error) {
 	args := m.Called(ctx, key)
 	if quote, ok := args.Get(0).(*quote.Quote); ok {
@@ -53,37 +86,87 @@ func (m *mockRepo) Save(ctx context.Context, q *quote.Quote) error {
 	return args.Error(0)
 }
 
+func (m *mockRepo) SaveLeased(ctx context.Context, quotes []*quote.Quote) error {
+	args := m.Called(ctx, quotes)
+	return args.Error(0)
+}
+
+func (m *mockRepo) ListPendingSystemCurrencies(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if currencies := args.Get(0); currencies != nil {
+		return currencies.([]string), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *mockRepo) Update(ctx context.Context, q *quote.Quote) error {
 	args := m.Called(ctx, q)
 	return args.Error(0)
 }
 
+func (m *mockRepo) PurgeRuns(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockRepo) PurgeOutbox(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockRepo) SaveRun(ctx context.Context, run *quote.RunSummary) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
 type mockFetcher struct {
 	mock.Mock
 }
 
-func (m *mockFetcher) FetchRates(ctx context.Context, base string, symbols []string) (map[string]float64, error) {
+func (m *mockFetcher) FetchRates(ctx context.Context, base string, symbols []string) (*exchange.Rates, error) {
 	args := m.Called(ctx, base, symbols)
-	if rates, ok := args.Get(0).(map[string]float64); ok {
+	if rates, ok := args.Get(0).(*exchange.Rates); ok {
 		return rates, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
+type mockPairs struct {
+	mock.Mock
+}
+
+func (m *mockPairs) Get(ctx context.Context, name string) (*pair.Pair, error) {
+	args := m.Called(ctx, name)
+	if p, ok := args.Get(0).(*pair.Pair); ok {
+		return p, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockPairs) List(ctx context.Context) ([]*pair.Pair, error) {
+	args := m.Called(ctx)
+	if p, ok := args.Get(0).([]*pair.Pair); ok {
+		return p, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func TestRequestUpdate_Success(t *testing.T) {
 	repo := new(mockRepo)
+	pairs := new(mockPairs)
 	fetcher := new(mockFetcher)
 
-	service := qs.New(repo, fetcher, log.NewZapLogger())
+	service := qs.New(repo, pairs, fetcher, nil, nil, log.NewZapLogger())
 
 	ctx := context.Background()
 	currency := "EUR/USD"
 	idemKey := uuid.NewString()
 
+	pairs.On("Get", ctx, currency).Return(&pair.Pair{Pair: currency, Enabled: true}, nil)
 	repo.On("GetByIdempotencyKey", ctx, idemKey).Return((*quote.Quote)(nil), nil)
 	repo.On("Save", ctx, mock.AnythingOfType("*quote.Quote")).Return(nil)
 
-	id, err := service.RequestUpdate(ctx, currency, idemKey)
+	id, err := service.RequestUpdate(ctx, currency, idemKey, "")
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, id)
@@ -93,15 +176,310 @@ func TestRequestUpdate_Success(t *testing.T) {
 
 func TestRequestUpdate_UnsupportedCurrency(t *testing.T) {
 	repo := new(mockRepo)
+	pairs := new(mockPairs)
 	fetcher := new(mockFetcher)
-	service := qs.New(repo, fetcher, log.NewZapLogger())
+	service := qs.New(repo, pairs, fetcher, nil, nil, log.NewZapLogger())
 
 	ctx := context.Background()
 	currency := "GBP/JPY"
 	idemKey := uuid.NewString()
 
-	id, err := service.RequestUpdate(ctx, currency, idemKey)
+	pairs.On("Get", ctx, currency).Return(nil, pair.ErrPairNotFound)
+
+	id, err := service.RequestUpdate(ctx, currency, idemKey, "")
+
+	assert.ErrorIs(t, err, quote.ErrUnsupportedCurrencyPair)
+	assert.Empty(t, id)
+}
+
+func TestRequestUpdate_DisabledCurrency(t *testing.T) {
+	repo := new(mockRepo)
+	pairs := new(mockPairs)
+	fetcher := new(mockFetcher)
+	service := qs.New(repo, pairs, fetcher, nil, nil, log.NewZapLogger())
+
+	ctx := context.Background()
+	currency := "EUR/RUB"
+
+	pairs.On("Get", ctx, currency).Return(&pair.Pair{Pair: currency, Enabled: false}, nil)
+
+	id, err := service.RequestUpdate(ctx, currency, uuid.NewString(), "")
 
 	assert.ErrorIs(t, err, quote.ErrUnsupportedCurrencyPair)
 	assert.Empty(t, id)
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestQuote_MarshalJSONUsesScale(t *testing.T) {
+	q := quote.Quote{
+		ID:       "q1",
+		Currency: "EUR/USD",
+		Amount:   decimal.RequireFromString("1.0823"),
+		Scale:    6,
+		Status:   quote.StatusDone,
+	}
+
+	data, err := json.Marshal(q)
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"amount":"1.082300"`)
+	assert.Contains(t, string(data), `"spread":"0.000000"`)
+	assert.Contains(t, string(data), `"status":"done"`)
+}
+
+func TestWaitByID_ReturnsOnTerminalUpdate(t *testing.T) {
+	repo := new(mockRepo)
+	updates := hub.New(log.NewZapLogger())
+	service := qs.New(repo, new(mockPairs), new(mockFetcher), updates, nil, log.NewZapLogger())
+
+	ctx := context.Background()
+	repo.On("GetByID", ctx, "q1").Return(&quote.Quote{ID: "q1", Status: quote.StatusInProgress}, nil)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		updates.Publish(&quote.Quote{ID: "q1", Status: quote.StatusDone, UpdatedAt: time.Now()})
+	}()
+	q, err := service.WaitByID(ctx, "q1", 5*time.Second)
+
+	assert.NoError(t, err)
+	assert.Equal(t, quote.StatusDone, q.Status)
+	repo.AssertNumberOfCalls(t, "GetByID", 1)
+}
+
+func TestWaitByID_ReturnsCurrentStateAfterTimeout(t *testing.T) {
+	repo := new(mockRepo)
+	service := qs.New(repo, new(mockPairs), new(mockFetcher), hub.New(log.NewZapLogger()), nil, log.NewZapLogger())
+
+	ctx := context.Background()
+	repo.On("GetByID", ctx, "q1").Return(&quote.Quote{ID: "q1", Status: quote.StatusInProgress}, nil)
+
+	q, err := service.WaitByID(ctx, "q1", 20*time.Millisecond)
+
+	assert.NoError(t, err)
+	assert.Equal(t, quote.StatusInProgress, q.Status)
+	repo.AssertNumberOfCalls(t, "GetByID", 2)
+}
+
+func TestStream_ReplaysMissedQuotesThenLiveUpdates(t *testing.T) {
+	repo := new(mockRepo)
+	updates := hub.New(log.NewZapLogger())
+	service := qs.New(repo, new(mockPairs), new(mockFetcher), updates, nil, log.NewZapLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	currencies := []string{"EUR/USD", "EUR/MXN"}
+	now := time.Now()
+	missed := &quote.Quote{ID: "q2", Currency: "EUR/MXN", Status: quote.StatusDone, UpdatedAt: now}
+	repo.On("ListDoneAfter", ctx, currencies, "q1", mock.AnythingOfType("int")).Return([]*quote.Quote{missed}, nil)
+
+	stream, err := service.Stream(ctx, currencies, "q1", 8)
+	assert.NoError(t, err)
+
+	updates.Publish(missed)
+	updates.Publish(&quote.Quote{ID: "q3", Currency: "EUR/RUB", Status: quote.StatusDone, UpdatedAt: now})
+	updates.Publish(&quote.Quote{ID: "q4", Currency: "EUR/USD", Status: quote.StatusFailed, UpdatedAt: now})
+	updates.Publish(&quote.Quote{ID: "q5", Currency: "EUR/USD", Status: quote.StatusDone, UpdatedAt: now})
+
+	assert.Equal(t, "q2", (<-stream).ID)
+	assert.Equal(t, "q5", (<-stream).ID)
+
+	cancel()
+	_, open := <-stream
+	assert.False(t, open)
+}
+
+func TestStream_ReplaysMissedQuotesPageByPage(t *testing.T) {
+	repo := new(mockRepo)
+	service := qs.New(repo, new(mockPairs), new(mockFetcher), hub.New(log.NewZapLogger()), nil, log.NewZapLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	currencies := []string{"EUR/USD"}
+	page := make([]*quote.Quote, 1000)
+	for i := range page {
+		page[i] = &quote.Quote{ID: fmt.Sprintf("p%d", i), Currency: "EUR/USD", Status: quote.StatusDone}
+	}
+	last := &quote.Quote{ID: "last", Currency: "EUR/USD", Status: quote.StatusDone}
+	repo.On("ListDoneAfter", ctx, currencies, "q1", 1000).Return(page, nil)
+	repo.On("ListDoneAfter", ctx, currencies, "p999", 1000).Return([]*quote.Quote{last}, nil)
+
+	stream, err := service.Stream(ctx, currencies, "q1", 8)
+	assert.NoError(t, err)
+
+	var received []string
+	for range 1001 {
+		received = append(received, (<-stream).ID)
+	}
+	assert.Equal(t, "p0", received[0])
+	assert.Equal(t, "last", received[1000])
+	repo.AssertExpectations(t)
+}
+
+func TestStream_ClosesWhenConsumerFallsBehind(t *testing.T) {
+	updates := hub.New(log.NewZapLogger())
+	service := qs.New(new(mockRepo), new(mockPairs), new(mockFetcher), updates, nil, log.NewZapLogger())
+
+	stream, err := service.Stream(context.Background(), []string{"EUR/USD"}, "", 1)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	for _, id := range []string{"q1", "q2", "q3", "q4"} {
+		updates.Publish(&quote.Quote{ID: id, Currency: "EUR/USD", Status: quote.StatusDone, UpdatedAt: now})
+	}
+
+	var received int
+	for range stream {
+		received++
+	}
+	assert.Less(t, received, 4)
+}
+
+func TestRequestUpdate_RejectsCallbackOutsideAllowList(t *testing.T) {
+	repo := new(mockRepo)
+	pairs := new(mockPairs)
+	callbacks := ws.New(nil, []string{"hooks.example.com"}, log.NewZapLogger())
+	service := qs.New(repo, pairs, new(mockFetcher), nil, callbacks, log.NewZapLogger())
+
+	ctx := context.Background()
+	id, err := service.RequestUpdate(ctx, "EUR/USD", uuid.NewString(), "https://attacker.test/cb")
+
+	assert.ErrorIs(t, err, webhook.ErrCallbackNotAllowed)
+	assert.Empty(t, id)
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestRequestUpdate_StoresCallbackURL(t *testing.T) {
+	repo := new(mockRepo)
+	pairs := new(mockPairs)
+	callbacks := ws.New(nil, []string{"hooks.example.com"}, log.NewZapLogger())
+	service := qs.New(repo, pairs, new(mockFetcher), nil, callbacks, log.NewZapLogger())
+
+	ctx := context.Background()
+	pairs.On("Get", ctx, "EUR/USD").Return(&pair.Pair{Pair: "EUR/USD", Enabled: true}, nil)
+	repo.On("Save", ctx, mock.MatchedBy(func(q *quote.Quote) bool {
+		return q.CallbackURL == "https://hooks.example.com/cb"
+	})).Return(nil)
+
+	_, err := service.RequestUpdate(ctx, "EUR/USD", "", "https://hooks.example.com/cb")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestGetLatest_ReturnsFreshQuoteWithAge(t *testing.T) {
+	repo := new(mockRepo)
+	service := qs.New(repo, new(mockPairs), new(mockFetcher), nil, nil, log.NewZapLogger())
+
+	ctx := context.Background()
+	repo.On("GetLatestByCurrency", ctx, "EUR/USD").Return(&quote.Quote{ID: "q1", Currency: "EUR/USD", Status: quote.StatusDone, UpdatedAt: time.Now().Add(-90 * time.Second)}, nil)
+
+	latest, err := service.GetLatest(ctx, "EUR/USD", 5*time.Minute, true)
+
+	assert.NoError(t, err)
+	assert.False(t, latest.Stale)
+	assert.Empty(t, latest.RefreshID)
+	data, _ := json.Marshal(latest.Quote)
+	assert.Contains(t, string(data), `"age_seconds":90`)
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestGetLatest_AgeIgnoresTimeZone(t *testing.T) {
+	repo := new(mockRepo)
+	service := qs.New(repo, new(mockPairs), new(mockFetcher), nil, nil, log.NewZapLogger())
+
+	ctx := context.Background()
+	for _, loc := range []*time.Location{time.FixedZone("JST", 9*3600), time.FixedZone("PDT", -7*3600)} {
+		updatedAt := time.Now().Add(-10 * time.Minute).In(loc)
+		repo.On("GetLatestByCurrency", ctx, "EUR/USD").Return(&quote.Quote{ID: "q1", Status: quote.StatusDone, UpdatedAt: updatedAt}, nil).Once()
+
+		latest, err := service.GetLatest(ctx, "EUR/USD", 5*time.Minute, false)
+
+		assert.NoError(t, err)
+		assert.True(t, latest.Stale, loc.String())
+		assert.InDelta(t, (10 * time.Minute).Seconds(), latest.Quote.Age.Seconds(), 1, loc.String())
+	}
+}
+
+func TestGetLatest_ConcurrentRefreshesShareOneUpdate(t *testing.T) {
+	repo := new(mockRepo)
+	pairs := new(mockPairs)
+	service := qs.New(repo, pairs, new(mockFetcher), nil, nil, log.NewZapLogger())
+
+	ctx := context.Background()
+	stale := &quote.Quote{ID: "q1", Status: quote.StatusDone, UpdatedAt: time.Now().Add(-time.Hour)}
+	repo.On("GetLatestByCurrency", ctx, "EUR/USD").Return(stale, nil)
+	repo.On("GetPendingByCurrency", ctx, "EUR/USD").Return(nil, nil)
+	pairs.On("Get", ctx, "EUR/USD").Return(&pair.Pair{Pair: "EUR/USD", Enabled: true}, nil)
+	var keys []string
+	repo.On("GetByIdempotencyKey", ctx, mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { keys = append(keys, args.String(1)) }).
+		Return(nil, nil).Twice()
+	winner := &quote.Quote{}
+	repo.On("Save", ctx, mock.AnythingOfType("*quote.Quote")).
+		Run(func(args mock.Arguments) { winner.ID = args.Get(1).(*quote.Quote).ID }).
+		Return(nil).Once()
+	// the second caller loses the insert race and gets the first update
+	repo.On("Save", ctx, mock.AnythingOfType("*quote.Quote")).Return(quote.ErrQuoteExists).Once()
+	repo.On("GetByIdempotencyKey", ctx, mock.AnythingOfType("string")).Return(winner, nil).Once()
+
+	first, err := service.GetLatest(ctx, "EUR/USD", 5*time.Minute, true)
+	assert.NoError(t, err)
+	second, err := service.GetLatest(ctx, "EUR/USD", 5*time.Minute, true)
+	assert.NoError(t, err)
+
+	assert.Equal(t, keys[0], keys[1])
+	assert.NotEmpty(t, first.RefreshID)
+	assert.Equal(t, first.RefreshID, second.RefreshID)
+}
+
+func TestGetLatest_StaleWithoutRefresh(t *testing.T) {
+	repo := new(mockRepo)
+	service := qs.New(repo, new(mockPairs), new(mockFetcher), nil, nil, log.NewZapLogger())
+
+	ctx := context.Background()
+	repo.On("GetLatestByCurrency", ctx, "EUR/USD").Return(&quote.Quote{ID: "q1", Status: quote.StatusDone, UpdatedAt: time.Now().Add(-time.Hour)}, nil)
+
+	latest, err := service.GetLatest(ctx, "EUR/USD", 5*time.Minute, false)
+
+	assert.NoError(t, err)
+	assert.True(t, latest.Stale)
+	assert.Empty(t, latest.RefreshID)
+}
+
+func TestGetLatest_StaleReusesPendingRefresh(t *testing.T) {
+	repo := new(mockRepo)
+	service := qs.New(repo, new(mockPairs), new(mockFetcher), nil, nil, log.NewZapLogger())
+
+	ctx := context.Background()
+	repo.On("GetLatestByCurrency", ctx, "EUR/USD").Return(&quote.Quote{ID: "q1", Status: quote.StatusDone, UpdatedAt: time.Now().Add(-time.Hour)}, nil)
+	repo.On("GetPendingByCurrency", ctx, "EUR/USD").Return(&quote.Quote{ID: "q2", Status: quote.StatusInProgress}, nil)
+
+	latest, err := service.GetLatest(ctx, "EUR/USD", 5*time.Minute, true)
+
+	assert.NoError(t, err)
+	assert.True(t, latest.Stale)
+	assert.Equal(t, "q2", latest.RefreshID)
+	repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestGetLatest_MissingQuoteRequestsUpdate(t *testing.T) {
+	repo := new(mockRepo)
+	pairs := new(mockPairs)
+	service := qs.New(repo, pairs, new(mockFetcher), nil, nil, log.NewZapLogger())
+
+	ctx := context.Background()
+	repo.On("GetLatestByCurrency", ctx, "EUR/USD").Return(nil, quote.ErrQuoteNotFound)
+	repo.On("GetPendingByCurrency", ctx, "EUR/USD").Return(nil, nil)
+	pairs.On("Get", ctx, "EUR/USD").Return(&pair.Pair{Pair: "EUR/USD", Enabled: true}, nil)
+	repo.On("GetByIdempotencyKey", ctx, mock.AnythingOfType("string")).Return(nil, nil)
+	repo.On("Save", ctx, mock.AnythingOfType("*quote.Quote")).Return(nil)
+
+	latest, err := service.GetLatest(ctx, "EUR/USD", 0, true)
+
+	assert.NoError(t, err)
+	assert.Nil(t, latest.Quote)
+	assert.NotEmpty(t, latest.RefreshID)
+
+	_, err = service.GetLatest(ctx, "EUR/USD", 0, false)
+	assert.ErrorIs(t, err, quote.ErrQuoteNotFound)
 }
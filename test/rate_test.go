@@ -0,0 +1,215 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"plata/internal/common/log"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"plata/internal/domain/rate"
+	rs "plata/internal/services/rate"
+)
+
+type mockRateRepo struct {
+	mock.Mock
+}
+
+func (m *mockRateRepo) History(ctx context.Context, q rate.HistoryQuery) ([]*rate.Rate, error) {
+	args := m.Called(ctx, q)
+	if rates, ok := args.Get(0).([]*rate.Rate); ok {
+		return rates, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRateRepo) GetAt(ctx context.Context, pair string, t time.Time) (*rate.Rate, error) {
+	args := m.Called(ctx, pair, t)
+	if r, ok := args.Get(0).(*rate.Rate); ok {
+		return r, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRateRepo) Candles(ctx context.Context, q rate.CandleQuery) ([]*rate.Candle, error) {
+	args := m.Called(ctx, q)
+	if candles, ok := args.Get(0).([]*rate.Candle); ok {
+		return candles, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRateRepo) MaterializeCandles(ctx context.Context, interval time.Duration, before time.Time) (int64, error) {
+	args := m.Called(ctx, interval, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestRateHistory_SetsCursorOnFullPage(t *testing.T) {
+	repo := new(mockRateRepo)
+	service := rs.New(repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	to := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	last := &rate.Rate{ID: 7, Pair: "EUR/USD", FetchedAt: to.Add(-time.Minute)}
+	repo.On("History", ctx, mock.MatchedBy(func(q rate.HistoryQuery) bool {
+		return q.Limit == 2
+	})).Return([]*rate.Rate{{ID: 3, Pair: "EUR/USD"}, last}, nil)
+
+	page, err := service.History(ctx, rate.HistoryQuery{Pair: "EUR/USD", From: to.Add(-time.Hour), To: to, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	cursor, err := rate.DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), cursor.ID)
+	assert.True(t, cursor.At.Equal(last.FetchedAt))
+}
+
+func TestRateHistory_LastPageHasNoCursor(t *testing.T) {
+	repo := new(mockRateRepo)
+	service := rs.New(repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	to := time.Now().UTC()
+	repo.On("History", ctx, mock.MatchedBy(func(q rate.HistoryQuery) bool {
+		return q.Limit == rate.DefaultLimit
+	})).Return([]*rate.Rate{{ID: 1, Pair: "EUR/USD"}}, nil)
+
+	page, err := service.History(ctx, rate.HistoryQuery{Pair: "EUR/USD", From: to.Add(-time.Hour), To: to})
+
+	assert.NoError(t, err)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestRateHistory_BucketCursorUsesBucketStart(t *testing.T) {
+	repo := new(mockRateRepo)
+	service := rs.New(repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	to := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	bucket := to.Add(-time.Hour)
+	repo.On("History", ctx, mock.Anything).Return([]*rate.Rate{{ID: 9, Pair: "EUR/USD", FetchedAt: bucket.Add(50 * time.Minute), Bucket: &bucket}}, nil)
+
+	page, err := service.History(ctx, rate.HistoryQuery{Pair: "EUR/USD", From: to.Add(-24 * time.Hour), To: to, Interval: time.Hour, Limit: 1})
+
+	assert.NoError(t, err)
+	cursor, err := rate.DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.True(t, cursor.At.Equal(bucket))
+	assert.Zero(t, cursor.ID)
+}
+
+func TestRateHistory_RejectsInvertedRange(t *testing.T) {
+	repo := new(mockRateRepo)
+	service := rs.New(repo, log.NewZapLogger())
+
+	now := time.Now()
+	_, err := service.History(context.Background(), rate.HistoryQuery{Pair: "EUR/USD", From: now, To: now.Add(-time.Hour)})
+
+	assert.ErrorIs(t, err, rate.ErrInvalidQuery)
+	repo.AssertNotCalled(t, "History", mock.Anything, mock.Anything)
+}
+
+func TestRateHistory_RejectsIntervalBelowMinimum(t *testing.T) {
+	repo := new(mockRateRepo)
+	service := rs.New(repo, log.NewZapLogger())
+
+	now := time.Now()
+	for _, interval := range []time.Duration{-time.Hour, time.Nanosecond, 500 * time.Millisecond} {
+		_, err := service.History(context.Background(), rate.HistoryQuery{Pair: "EUR/USD", From: now.Add(-time.Hour), To: now, Interval: interval})
+
+		assert.ErrorIs(t, err, rate.ErrInvalidQuery, interval.String())
+	}
+	repo.AssertNotCalled(t, "History", mock.Anything, mock.Anything)
+}
+
+func TestGetRateAt_ReturnsLastKnownRate(t *testing.T) {
+	repo := new(mockRateRepo)
+	service := rs.New(repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	at := time.Date(2024, 5, 1, 14, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	want := &rate.Rate{Pair: "EUR/USD", Rate: decimal.RequireFromString("1.0823"), Scale: 4}
+	repo.On("GetAt", ctx, "EUR/USD", at.UTC()).Return(want, nil)
+
+	r, err := service.GetRateAt(ctx, "EUR/USD", at)
+
+	assert.NoError(t, err)
+	assert.Equal(t, want, r)
+	data, _ := json.Marshal(r)
+	assert.Contains(t, string(data), `"rate":"1.0823"`)
+}
+
+func TestGetRateAt_NotFound(t *testing.T) {
+	repo := new(mockRateRepo)
+	service := rs.New(repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	repo.On("GetAt", ctx, "EUR/USD", mock.Anything).Return(nil, rate.ErrRateNotFound)
+
+	_, err := service.GetRateAt(ctx, "EUR/USD", time.Now())
+
+	assert.ErrorIs(t, err, rate.ErrRateNotFound)
+}
+
+func TestDecodeCursor_RejectsGarbage(t *testing.T) {
+	_, err := rate.DecodeCursor("not a cursor")
+
+	assert.ErrorIs(t, err, rate.ErrInvalidCursor)
+}
+
+func TestCandles_ReturnsSeries(t *testing.T) {
+	repo := new(mockRateRepo)
+	service := rs.New(repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	to := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	q := rate.CandleQuery{Pair: "EUR/USD", From: to.Add(-2 * time.Hour), To: to, Interval: time.Hour}
+	candle := &rate.Candle{
+		Bucket:  to.Add(-time.Hour),
+		Open:    decimal.RequireFromString("1.08"),
+		High:    decimal.RequireFromString("1.09"),
+		Low:     decimal.RequireFromString("1.07"),
+		Close:   decimal.RequireFromString("1.085"),
+		Scale:   4,
+		Samples: 3,
+	}
+	repo.On("Candles", ctx, q).Return([]*rate.Candle{candle}, nil)
+
+	series, err := service.Candles(ctx, q)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1h0m0s", series.Interval)
+	data, _ := json.Marshal(series.Candles[0])
+	assert.JSONEq(t, `{"time":"2024-05-01T11:00:00Z","open":"1.0800","high":"1.0900","low":"1.0700","close":"1.0850","samples":3}`, string(data))
+}
+
+func TestCandles_RejectsInvalidInterval(t *testing.T) {
+	repo := new(mockRateRepo)
+	service := rs.New(repo, log.NewZapLogger())
+
+	to := time.Now().UTC()
+	for _, interval := range []time.Duration{0, time.Millisecond, 1500 * time.Millisecond} {
+		_, err := service.Candles(context.Background(), rate.CandleQuery{Pair: "EUR/USD", From: to.Add(-24 * time.Hour), To: to, Interval: interval})
+
+		assert.ErrorIs(t, err, rate.ErrInvalidQuery, interval.String())
+		assert.ErrorContains(t, err, "whole number of seconds", interval.String())
+	}
+	repo.AssertNotCalled(t, "Candles", mock.Anything, mock.Anything)
+}
+
+func TestCandles_RejectsTooManyBuckets(t *testing.T) {
+	repo := new(mockRateRepo)
+	service := rs.New(repo, log.NewZapLogger())
+
+	to := time.Now().UTC()
+	from := to.Add(-(rate.MaxCandles + 1) * time.Second)
+	_, err := service.Candles(context.Background(), rate.CandleQuery{Pair: "EUR/USD", From: from, To: to, Interval: time.Second})
+
+	assert.ErrorIs(t, err, rate.ErrInvalidQuery)
+	assert.ErrorContains(t, err, "more than 5000 candles")
+	repo.AssertNotCalled(t, "Candles", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"plata/internal/domain/quote"
+	"plata/internal/domain/webhook"
+	qr "plata/internal/repository/quote"
+	wr "plata/internal/repository/webhook"
+)
+
+func TestWebhookRepository_RecordRejectsLateAttemptAfterDelivery(t *testing.T) {
+	db := newTestDB(t)
+	repo := wr.New(db, db)
+	ctx := context.Background()
+
+	now := time.Now()
+	q := &quote.Quote{ID: uuid.NewString(), Currency: "EUR/USD", Status: quote.StatusInProgress, Origin: quote.OriginRequest, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, qr.New(db, db, false).Save(ctx, q))
+	id := uuid.NewString()
+	_, err := db.Exec(`INSERT INTO webhook_deliveries (id, quote_id, url, event, payload, next_attempt_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		id, q.ID, "https://hooks.example.com/plata", "quote.done", "{}", now.UTC())
+	require.NoError(t, err)
+
+	// worker-1 claims with a lease that has already run out when worker-2 claims.
+	slow, err := repo.ClaimDue(ctx, "worker-1", now, -time.Second, 1)
+	require.NoError(t, err)
+	require.Len(t, slow, 1)
+	fast, err := repo.ClaimDue(ctx, "worker-2", now, time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, fast, 1)
+
+	delivered := time.Now()
+	fast[0].Status = webhook.StatusDelivered
+	fast[0].Attempts = 1
+	fast[0].DeliveredAt = &delivered
+	require.NoError(t, repo.Record(ctx, fast[0], &webhook.Attempt{DeliveryID: id, Attempt: 1, StatusCode: 200, AttemptedAt: delivered}))
+
+	retry := time.Now().Add(time.Minute)
+	slow[0].Attempts = 1
+	slow[0].LastError = "timeout"
+	slow[0].NextAttemptAt = &retry
+	err = repo.Record(ctx, slow[0], &webhook.Attempt{DeliveryID: id, Attempt: 1, Error: "timeout", AttemptedAt: time.Now()})
+	assert.ErrorIs(t, err, webhook.ErrLeaseLost)
+
+	deliveries, err := repo.ListByQuote(ctx, q.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, webhook.StatusDelivered, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Len(t, deliveries[0].Log, 1)
+}
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"plata/internal/common/log"
+	"plata/internal/config"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	wh "plata/internal/app/webhook"
+	"plata/internal/domain/webhook"
+)
+
+type mockDeliveryRepo struct {
+	mock.Mock
+}
+
+func (m *mockDeliveryRepo) ClaimDue(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	args := m.Called(ctx, owner, now, lease, limit)
+	if deliveries, ok := args.Get(0).([]*webhook.Delivery); ok {
+		return deliveries, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockDeliveryRepo) Record(ctx context.Context, d *webhook.Delivery, attempt *webhook.Attempt) error {
+	args := m.Called(ctx, d, attempt)
+	return args.Error(0)
+}
+
+func (m *mockDeliveryRepo) ListByQuote(ctx context.Context, quoteID string) ([]*webhook.Delivery, error) {
+	args := m.Called(ctx, quoteID)
+	if deliveries, ok := args.Get(0).([]*webhook.Delivery); ok {
+		return deliveries, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func onClaimDeliveries(repo *mockDeliveryRepo, ctx context.Context) *mock.Call {
+	return repo.On("ClaimDue", ctx,
+		mock.AnythingOfType("string"),
+		mock.AnythingOfType("time.Time"),
+		mock.AnythingOfType("time.Duration"),
+		mock.AnythingOfType("int"),
+	)
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	secret := "s3cret"
+	payload := `{"event":"quote.done","quote":{"id":"q1"}}`
+	received := make(chan *http.Request, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if r.Header.Get(webhook.HeaderSignature) != webhook.Sign([]byte(secret), ts, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repo := new(mockDeliveryRepo)
+	dispatcher := wh.New(config.WebhookConfig{Secret: secret, BatchSize: 10}, "replica-1", repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	del := &webhook.Delivery{ID: "d1", QuoteID: "q1", URL: receiver.URL, Event: "quote.done", Payload: payload, Status: webhook.StatusPending}
+	onClaimDeliveries(repo, ctx).Return([]*webhook.Delivery{del}, nil).Once()
+	repo.On("Record", ctx, del, mock.AnythingOfType("*webhook.Attempt")).Return(nil)
+
+	assert.Equal(t, 1, dispatcher.DispatchDue(ctx))
+
+	require.Len(t, received, 1)
+	r := <-received
+	assert.Equal(t, "d1", r.Header.Get(webhook.HeaderDelivery))
+	assert.Equal(t, "quote.done", r.Header.Get(webhook.HeaderEvent))
+	assert.Equal(t, webhook.StatusDelivered, del.Status)
+	assert.NotNil(t, del.DeliveredAt)
+	attempt := repo.Calls[1].Arguments.Get(2).(*webhook.Attempt)
+	assert.Equal(t, http.StatusNoContent, attempt.StatusCode)
+	assert.Equal(t, 1, attempt.Attempt)
+}
+
+func TestDispatcher_SchedulesRetryOnErrorStatus(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	repo := new(mockDeliveryRepo)
+	dispatcher := wh.New(config.WebhookConfig{
+		MaxAttempts: 3,
+		BatchSize:   10,
+		Retry:       config.RetryConfig{BaseDelay: time.Minute, MaxDelay: time.Hour},
+	}, "replica-1", repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	del := &webhook.Delivery{ID: "d1", URL: receiver.URL, Payload: "{}", Status: webhook.StatusPending, Attempts: 1}
+	onClaimDeliveries(repo, ctx).Return([]*webhook.Delivery{del}, nil).Once()
+	repo.On("Record", ctx, del, mock.AnythingOfType("*webhook.Attempt")).Return(nil)
+
+	start := time.Now()
+	dispatcher.DispatchDue(ctx)
+
+	assert.Equal(t, webhook.StatusPending, del.Status)
+	assert.Equal(t, 2, del.Attempts)
+	assert.Contains(t, del.LastError, "502")
+	require.NotNil(t, del.NextAttemptAt)
+	assert.WithinDuration(t, start.Add(2*time.Minute), *del.NextAttemptAt, time.Second)
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	var followed bool
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer internal.Close()
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL, http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+
+	repo := new(mockDeliveryRepo)
+	dispatcher := wh.New(config.WebhookConfig{MaxAttempts: 3}, "replica-1", repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	del := &webhook.Delivery{ID: "d1", URL: receiver.URL, Payload: "{}", Status: webhook.StatusPending}
+	onClaimDeliveries(repo, ctx).Return([]*webhook.Delivery{del}, nil).Once()
+	repo.On("Record", ctx, del, mock.AnythingOfType("*webhook.Attempt")).Return(nil)
+
+	dispatcher.DispatchDue(ctx)
+
+	assert.False(t, followed)
+	assert.Equal(t, webhook.StatusPending, del.Status)
+	assert.Contains(t, del.LastError, "307")
+}
+
+func TestDispatcher_KeepsCallbackPathOutOfErrors(t *testing.T) {
+	repo := new(mockDeliveryRepo)
+	dispatcher := wh.New(config.WebhookConfig{MaxAttempts: 3, Timeout: time.Second}, "replica-1", repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	del := &webhook.Delivery{ID: "d1", URL: "http://127.0.0.1:1/plata/s3cr3t-token", Payload: "{}", Status: webhook.StatusPending}
+	onClaimDeliveries(repo, ctx).Return([]*webhook.Delivery{del}, nil).Once()
+	repo.On("Record", ctx, del, mock.AnythingOfType("*webhook.Attempt")).Return(nil)
+
+	dispatcher.DispatchDue(ctx)
+
+	assert.Contains(t, del.LastError, "127.0.0.1:1")
+	assert.NotContains(t, del.LastError, "s3cr3t-token")
+}
+
+func TestDispatcher_FailsAfterMaxAttempts(t *testing.T) {
+	repo := new(mockDeliveryRepo)
+	dispatcher := wh.New(config.WebhookConfig{MaxAttempts: 2, BatchSize: 10, Timeout: time.Second}, "replica-1", repo, log.NewZapLogger())
+
+	ctx := context.Background()
+	del := &webhook.Delivery{ID: "d1", URL: "http://127.0.0.1:1/unreachable", Payload: "{}", Status: webhook.StatusPending, Attempts: 1}
+	onClaimDeliveries(repo, ctx).Return([]*webhook.Delivery{del}, nil).Once()
+	repo.On("Record", ctx, del, mock.AnythingOfType("*webhook.Attempt")).Return(nil)
+
+	dispatcher.DispatchDue(ctx)
+
+	assert.Equal(t, webhook.StatusFailed, del.Status)
+	assert.Nil(t, del.NextAttemptAt)
+	assert.NotEmpty(t, del.LastError)
+}
+
+func TestAllowList_Check(t *testing.T) {
+	allowed := webhook.AllowList{"hooks.example.com", ".partner.io"}
+
+	assert.NoError(t, allowed.Check("https://hooks.example.com/quotes"))
+	assert.NoError(t, allowed.Check("https://eu.partner.io/cb"))
+	assert.ErrorIs(t, allowed.Check("https://partner.io.evil.com/cb"), webhook.ErrCallbackNotAllowed)
+	assert.ErrorIs(t, allowed.Check("https://example.com/"), webhook.ErrCallbackNotAllowed)
+	assert.ErrorIs(t, allowed.Check("ftp://hooks.example.com/"), webhook.ErrInvalidCallbackURL)
+	assert.ErrorIs(t, allowed.Check("not a url"), webhook.ErrInvalidCallbackURL)
+}